@@ -35,14 +35,41 @@ type ALBAuthSpec struct {
 	Issuer  string `json:"issuer,omitempty"`
 }
 
+// OverwrittenAnnotationsAnnotation records on an Ingress the values of the
+// annotations an ALBAuth overwrote, they are restored when the ALBAuth is deleted
+const OverwrittenAnnotationsAnnotation = "dex.betssongroup.com/overwritten-annotations"
+
+// ALBSecretHashAnnotation holds the SHA-256 of the client credentials in the Secret
+// of an ALBAuth, it changes whenever the credentials are updated
+const ALBSecretHashAnnotation = "dex.betssongroup.com/credentials-hash"
+
 // ALBAuthStatus defines the observed state of ALBAuth
 type ALBAuthStatus struct {
 	Ingress corev1.ObjectReference `json:"ingress,omitempty"`
 	Secret  corev1.ObjectReference `json:"secret,omitempty"`
 	State   string                 `json:"state,omitempty"`
+
+	// +optional
+
+	// The generation of the spec last applied to the Ingress
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+
+	// Conditions of the ALBAuth
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ingress",type=string,JSONPath=`.spec.ingress`
+// +kubebuilder:printcolumn:name="Client",type=string,JSONPath=`.spec.client`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ALBAuth is the Schema for the albauths API
 type ALBAuth struct {
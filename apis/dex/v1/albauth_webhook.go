@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var albauthlog = logf.Log.WithName("albauth-resource")
+
+// SetupWebhookWithManager registers the validating webhook of ALBAuth
+func (r *ALBAuth) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-dex-betssongroup-com-v1-albauth,mutating=false,failurePolicy=fail,sideEffects=None,groups=dex.betssongroup.com,resources=albauths,verbs=create;update,versions=v1,name=valbauth.dex.betssongroup.com,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &ALBAuth{}
+
+// ValidateCreate implements webhook.Validator
+func (r *ALBAuth) ValidateCreate() error {
+	albauthlog.V(1).Info("validate create", "name", r.Name)
+	return r.validate(context.Background())
+}
+
+// ValidateUpdate implements webhook.Validator, updates which leave the spec unchanged
+// like finalizer removal are always allowed.
+func (r *ALBAuth) ValidateUpdate(old runtime.Object) error {
+	albauthlog.V(1).Info("validate update", "name", r.Name)
+	if r.DeletionTimestamp != nil || reflect.DeepEqual(r.Spec, old.(*ALBAuth).Spec) {
+		return nil
+	}
+	return r.validate(context.Background())
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *ALBAuth) ValidateDelete() error {
+	return nil
+}
+
+func (r *ALBAuth) validate(ctx context.Context) error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+
+	if issuer, err := url.Parse(r.Spec.Issuer); err != nil || issuer.Scheme != "https" || issuer.Host == "" {
+		errs = append(errs, field.Invalid(spec.Child("issuer"), r.Spec.Issuer, "must be an https URL"))
+	}
+
+	if r.Spec.Ingress == "" {
+		errs = append(errs, field.Required(spec.Child("ingress"), "the ingress to authenticate is required"))
+	} else if webhookClient != nil {
+		ingress := &extensionsv1beta1.Ingress{}
+		err := webhookClient.Get(ctx, k8stypes.NamespacedName{Name: r.Spec.Ingress, Namespace: r.Namespace}, ingress)
+		if apierrors.IsNotFound(err) {
+			errs = append(errs, field.NotFound(spec.Child("ingress"), r.Spec.Ingress))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if r.Spec.Client == "" {
+		errs = append(errs, field.Required(spec.Child("client"), "the client to authenticate with is required"))
+	} else if webhookClient != nil {
+		dexClient := &Client{}
+		err := webhookClient.Get(ctx, k8stypes.NamespacedName{Name: r.Spec.Client, Namespace: r.Namespace}, dexClient)
+		if apierrors.IsNotFound(err) {
+			errs = append(errs, field.NotFound(spec.Child("client"), r.Spec.Client))
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ALBAuth").GroupKind(), r.Name, errs)
+}
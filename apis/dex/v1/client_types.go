@@ -29,14 +29,68 @@ type ClientSpec struct {
 	// The name of the oidc config
 	Name string `json:"name,omitempty"`
 
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+
+	// ID of the client in Dex, derived from the metadata by the client ID strategy of
+	// the operator when not set. It can not be changed once the client is created.
+	ClientID string `json:"clientID,omitempty"`
+
+	// +optional
+
+	// DexServer the client is created in, defaults to the Dex instance configured on
+	// the operator. It can not be changed once the client is created.
+	DexServerRef *DexServerReference `json:"dexServerRef,omitempty"`
+
 	// +kubebuilder:validation:MinLength=2
-	// +kubebuilder:validation:Required
+	// +optional
 
-	// The shared oidc secret
+	// The shared oidc secret.
+	// Deprecated: the inline secret is stored in plaintext, use SecretRef instead.
 	Secret string `json:"secret,omitempty"`
 
 	// +optional
 
+	// Reference to a Secret key holding the shared oidc secret
+	SecretRef *SecretReference `json:"secretRef,omitempty"`
+
+	// +optional
+
+	// Let the operator generate the shared oidc secret into an owned Secret
+	SecretGeneration *SecretGeneration `json:"secretGeneration,omitempty"`
+
+	// +optional
+
+	// Rotation of the generated oidc secret
+	Rotation *SecretRotation `json:"rotation,omitempty"`
+
+	// +kubebuilder:validation:Enum=Never;IfMatching;Always
+	// +optional
+
+	// Whether an existing Dex client with the same ID is adopted, defaults to Never.
+	// IfMatching adopts it when its secret and public flag match the spec, Always
+	// recreates it when they do not. Clients may only use Always in the namespaces
+	// listed in --always-adoption-namespaces of the operator.
+	AdoptionPolicy string `json:"adoptionPolicy,omitempty"`
+
+	// +kubebuilder:validation:Enum=Recreate;Reject
+	// +optional
+
+	// How changes to fields Dex can not update in place, the secret and the public
+	// flag, are applied, defaults to Recreate. Recreate deletes and creates the Dex
+	// client again, Reject refuses the change until it is reverted.
+	UpdateStrategy string `json:"updateStrategy,omitempty"`
+
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+
+	// Whether the Dex client is deleted with the Client, defaults to the
+	// --default-deletion-policy of the operator. Retain leaves it in Dex to be
+	// adopted by a new Client, e.g. when moving the Client to another namespace.
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// +optional
+
 	// Sets the public flag
 	Public bool `json:"public,omitempty"`
 
@@ -54,19 +108,166 @@ type ClientSpec struct {
 	LogoURL string `json:"logoURL,omitempty"`
 }
 
+// SecretReference selects a key of a Secret
+type SecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Name of the Secret
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// Key in the Secret data holding the value
+	Key string `json:"key"`
+
+	// +optional
+
+	// Namespace of the Secret, defaults to the namespace of the referring object and
+	// is required by ClusterClients
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DexServerReference selects a DexServer
+type DexServerReference struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Name of the DexServer
+	Name string `json:"name"`
+}
+
+// SecretGeneration configures an operator generated client secret
+type SecretGeneration struct {
+	// +kubebuilder:validation:Minimum=16
+	// +kubebuilder:validation:Maximum=256
+	// +optional
+
+	// Length of the generated secret, defaults to 40
+	Length int `json:"length,omitempty"`
+
+	// +kubebuilder:validation:Enum=alphanumeric;hex;urlsafe
+	// +optional
+
+	// Characters used in the generated secret, defaults to alphanumeric
+	Charset string `json:"charset,omitempty"`
+
+	// +optional
+
+	// Namespace of the generated Secret, required by ClusterClients and ignored by
+	// Clients, which keep it in their own namespace
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DefaultSecretLength is the length of generated secrets when not set
+const DefaultSecretLength = 40
+
+// Charsets of generated client secrets
+const (
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetHex          = "hex"
+	CharsetURLSafe      = "urlsafe"
+)
+
+// Adoption policies of existing Dex clients
+const (
+	AdoptionPolicyNever      = "Never"
+	AdoptionPolicyIfMatching = "IfMatching"
+	AdoptionPolicyAlways     = "Always"
+)
+
+// Strategies to apply changes to immutable fields of Dex clients
+const (
+	UpdateStrategyRecreate = "Recreate"
+	UpdateStrategyReject   = "Reject"
+)
+
+// Policies for the Dex client when a Client is deleted
+const (
+	DeletionPolicyDelete = "Delete"
+	DeletionPolicyRetain = "Retain"
+)
+
+// RotateSecretAnnotation requests a rotation of a generated client secret on demand
+const RotateSecretAnnotation = "dex.betssongroup.com/rotate-secret"
+
+// ForceDeleteAnnotation lets a deleted client go without deleting it from Dex, e.g.
+// when Dex is gone for good
+const ForceDeleteAnnotation = "dex.betssongroup.com/force-delete"
+
+// SecretRotation configures the rotation of generated client secrets
+type SecretRotation struct {
+	// Interval between rotations, e.g. 2160h for 90 days
+	Interval metav1.Duration `json:"interval"`
+}
+
 // ClientStatus defines the observed state of Client
 type ClientStatus struct {
 
 	// +optional
 
+	// Phase of the client, a summary of the conditions kept for compatibility
 	State string `json:"state,omitempty"`
 
 	// +optional
 
 	Message string `json:"message,omitempty"`
+
+	// +optional
+
+	// ID of the client in Dex, set once the client is created or adopted
+	ClientID string `json:"clientID,omitempty"`
+
+	// +optional
+
+	// DexServer the client is created in, empty for the default Dex instance
+	DexServer string `json:"dexServer,omitempty"`
+
+	// +optional
+
+	// HMAC-SHA256 of the client secret last pushed to Dex, used to detect secret
+	// changes. It is keyed with a key held by the operator.
+	SecretHash string `json:"secretHash,omitempty"`
+
+	// +optional
+
+	// Public flag of the client last pushed to Dex, used to detect changes
+	Public *bool `json:"public,omitempty"`
+
+	// +optional
+
+	// Time of the last client secret rotation
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// +optional
+
+	// The generation of the spec last reconciled with Dex
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+
+	// Number of consecutive failed attempts to create the client in Dex
+	FailedAttempts int32 `json:"failedAttempts,omitempty"`
+
+	// +optional
+
+	// Time of the next attempt to create a failed client, not set when the failure
+	// is permanent and the client waits for a spec change
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+
+	// Conditions of the client
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Client ID",type=string,JSONPath=`.status.clientID`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Client is the Schema for the clients API
 type Client struct {
@@ -86,6 +287,16 @@ type ClientList struct {
 	Items           []Client `json:"items"`
 }
 
+// GetClientSpec implements ClientObject
+func (c *Client) GetClientSpec() *ClientSpec {
+	return &c.Spec
+}
+
+// GetClientStatus implements ClientObject
+func (c *Client) GetClientStatus() *ClientStatus {
+	return &c.Status
+}
+
 func init() {
 	SchemeBuilder.Register(&Client{}, &ClientList{})
 }
@@ -0,0 +1,252 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// MinSecretLength is the minimum length of client secrets
+	MinSecretLength = 16
+	// oobRedirectURI is the out-of-band redirect URI Dex accepts for public clients
+	oobRedirectURI = "urn:ietf:wg:oauth:2.0:oob"
+)
+
+var clientlog = logf.Log.WithName("client-resource")
+
+// webhookClient reads the resources referenced by validated objects
+var webhookClient client.Reader
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of Client
+func (r *Client) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dex-betssongroup-com-v1-client,mutating=true,failurePolicy=fail,sideEffects=None,groups=dex.betssongroup.com,resources=clients,verbs=create;update,versions=v1,name=mclient.dex.betssongroup.com,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &Client{}
+
+// Default implements webhook.Defaulter
+func (r *Client) Default() {
+	clientlog.V(1).Info("default", "name", r.Name)
+	defaultClient(r)
+}
+
+// defaultClient defaults the spec shared by Client and ClusterClient
+func defaultClient(r ClientObject) {
+	spec := r.GetClientSpec()
+	if spec.Name == "" {
+		spec.Name = r.GetName()
+	}
+	if spec.AdoptionPolicy == "" {
+		spec.AdoptionPolicy = AdoptionPolicyNever
+	}
+	if spec.UpdateStrategy == "" {
+		spec.UpdateStrategy = UpdateStrategyRecreate
+	}
+	if generation := spec.SecretGeneration; generation != nil {
+		if generation.Length == 0 {
+			generation.Length = DefaultSecretLength
+		}
+		if generation.Charset == "" {
+			generation.Charset = CharsetAlphanumeric
+		}
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-dex-betssongroup-com-v1-client,mutating=false,failurePolicy=fail,sideEffects=None,groups=dex.betssongroup.com,resources=clients,verbs=create;update,versions=v1,name=vclient.dex.betssongroup.com,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &Client{}
+
+// ValidateCreate implements webhook.Validator
+func (r *Client) ValidateCreate() error {
+	clientlog.V(1).Info("validate create", "name", r.Name)
+	return validateClient(context.Background(), "Client", r, nil)
+}
+
+// ValidateUpdate implements webhook.Validator, updates which leave the spec unchanged
+// like finalizer removal are always allowed.
+func (r *Client) ValidateUpdate(old runtime.Object) error {
+	clientlog.V(1).Info("validate update", "name", r.Name)
+	oldClient := old.(*Client)
+	if r.DeletionTimestamp != nil || reflect.DeepEqual(r.Spec, oldClient.Spec) {
+		return nil
+	}
+	return validateClient(context.Background(), "Client", r, oldClient)
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *Client) ValidateDelete() error {
+	return nil
+}
+
+// validateClient validates the spec shared by Client and ClusterClient, old is nil
+// on creation and kind is reported in the error
+func validateClient(ctx context.Context, kind string, r ClientObject, old ClientObject) error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	clientSpec := r.GetClientSpec()
+	server := ""
+	if clientSpec.DexServerRef != nil {
+		server = clientSpec.DexServerRef.Name
+	}
+
+	for i, uri := range clientSpec.RedirectURIs {
+		path := spec.Child("redirectURIs").Index(i)
+		if uri == "" {
+			errs = append(errs, field.Required(path, "redirect URIs can not be empty"))
+		} else if !absoluteURL(uri) && !(clientSpec.Public && uri == oobRedirectURI) {
+			errs = append(errs, field.Invalid(path, uri, "must be an absolute URL"))
+		}
+	}
+
+	// Public clients can not keep a secret, confidential clients need one
+	if !clientSpec.Public && clientSpec.Secret == "" && clientSpec.SecretRef == nil && clientSpec.SecretGeneration == nil {
+		errs = append(errs, field.Required(spec.Child("secretRef"), "confidential clients need secretRef or secretGeneration"))
+	}
+	if clientSpec.Secret != "" && len(clientSpec.Secret) < MinSecretLength {
+		errs = append(errs, secretTooShort(spec.Child("secret"), len(clientSpec.Secret)))
+	}
+	if ref := clientSpec.SecretRef; ref != nil {
+		namespace := r.GetNamespace()
+		if namespace == "" {
+			namespace = ref.Namespace
+		}
+		if namespace == "" {
+			errs = append(errs, field.Required(spec.Child("secretRef", "namespace"), "cluster scoped clients need the namespace of the Secret"))
+		} else if webhookClient != nil {
+			// The Secret may be created after the client, only existing Secrets are checked
+			secret := &corev1.Secret{}
+			err := webhookClient.Get(ctx, k8stypes.NamespacedName{Name: ref.Name, Namespace: namespace}, secret)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+			if value, ok := secret.Data[ref.Key]; err == nil && ok && len(value) < MinSecretLength {
+				errs = append(errs, secretTooShort(spec.Child("secretRef"), len(value)))
+			}
+		}
+	}
+	if generation := clientSpec.SecretGeneration; generation != nil && r.GetNamespace() == "" && generation.Namespace == "" {
+		errs = append(errs, field.Required(spec.Child("secretGeneration", "namespace"), "cluster scoped clients need the namespace of the generated Secret"))
+	}
+
+	if old != nil {
+		oldSpec, oldStatus := old.GetClientSpec(), old.GetClientStatus()
+		if oldStatus.ClientID != "" && clientSpec.ClientID != oldSpec.ClientID && clientSpec.ClientID != oldStatus.ClientID {
+			errs = append(errs, field.Forbidden(spec.Child("clientID"), "can not be changed once the client is created"))
+		}
+		if oldStatus.ClientID != "" && server != oldStatus.DexServer {
+			errs = append(errs, field.Forbidden(spec.Child("dexServerRef"), "can not be changed once the client is created"))
+		}
+	}
+
+	if len(clientSpec.TrustedPeers) > 0 && webhookClient != nil {
+		ids, err := knownClientIDs(ctx, server)
+		if err != nil {
+			return err
+		}
+		for i, peer := range clientSpec.TrustedPeers {
+			if !ids[peer] {
+				errs = append(errs, field.NotFound(spec.Child("trustedPeers").Index(i), peer))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(kind).GroupKind(), r.GetName(), errs)
+}
+
+// absoluteURL returns true for URLs with a scheme and a host
+func absoluteURL(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// secretTooShort reports a short secret without its value
+func secretTooShort(path *field.Path, length int) *field.Error {
+	return field.Invalid(path, fmt.Sprintf("%d characters", length),
+		fmt.Sprintf("the secret must have at least %d characters", MinSecretLength))
+}
+
+// knownClientIDs returns the dex client IDs of all Clients and ClusterClients in the
+// dex server
+func knownClientIDs(ctx context.Context, server string) (map[string]bool, error) {
+	clients := &ClientList{}
+	if err := webhookClient.List(ctx, clients); err != nil {
+		return nil, err
+	}
+	clusterClients := &ClusterClientList{}
+	if err := webhookClient.List(ctx, clusterClients); err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for i := range clients.Items {
+		if dexServerName(&clients.Items[i]) == server {
+			addClientIDs(ids, &clients.Items[i])
+		}
+	}
+	for i := range clusterClients.Items {
+		if dexServerName(&clusterClients.Items[i]) == server {
+			addClientIDs(ids, &clusterClients.Items[i])
+		}
+	}
+	return ids, nil
+}
+
+// dexServerName returns the DexServer a client is or will be created in, empty for
+// the default dex instance
+func dexServerName(c ClientObject) string {
+	if server := c.GetClientStatus().DexServer; server != "" {
+		return server
+	}
+	if ref := c.GetClientSpec().DexServerRef; ref != nil {
+		return ref.Name
+	}
+	return ""
+}
+
+// addClientIDs adds the dex client IDs a client owns or may own
+func addClientIDs(ids map[string]bool, c ClientObject) {
+	spec, status := c.GetClientSpec(), c.GetClientStatus()
+	if status.ClientID != "" {
+		ids[status.ClientID] = true
+	} else {
+		ids[c.GetName()] = true
+	}
+	if spec.ClientID != "" {
+		ids[spec.ClientID] = true
+	}
+}
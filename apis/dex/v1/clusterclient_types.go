@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:object:generate=false
+
+// ClientObject is implemented by the Client and ClusterClient kinds, which share
+// their spec and status
+type ClientObject interface {
+	metav1.Object
+	runtime.Object
+	GetClientSpec() *ClientSpec
+	GetClientStatus() *ClientStatus
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Client ID",type=string,JSONPath=`.status.clientID`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterClient is the Schema for the cluster scoped clusterclients API, used for
+// clients of the whole platform
+type ClusterClient struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClientSpec   `json:"spec,omitempty"`
+	Status ClientStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterClientList contains a list of ClusterClient
+type ClusterClientList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterClient `json:"items"`
+}
+
+// GetClientSpec implements ClientObject
+func (c *ClusterClient) GetClientSpec() *ClientSpec {
+	return &c.Spec
+}
+
+// GetClientStatus implements ClientObject
+func (c *ClusterClient) GetClientStatus() *ClientStatus {
+	return &c.Status
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterClient{}, &ClusterClientList{})
+}
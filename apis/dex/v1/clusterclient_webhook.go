@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var clusterclientlog = logf.Log.WithName("clusterclient-resource")
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of ClusterClient
+func (r *ClusterClient) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-dex-betssongroup-com-v1-clusterclient,mutating=true,failurePolicy=fail,sideEffects=None,groups=dex.betssongroup.com,resources=clusterclients,verbs=create;update,versions=v1,name=mclusterclient.dex.betssongroup.com,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &ClusterClient{}
+
+// Default implements webhook.Defaulter
+func (r *ClusterClient) Default() {
+	clusterclientlog.V(1).Info("default", "name", r.Name)
+	defaultClient(r)
+}
+
+// +kubebuilder:webhook:path=/validate-dex-betssongroup-com-v1-clusterclient,mutating=false,failurePolicy=fail,sideEffects=None,groups=dex.betssongroup.com,resources=clusterclients,verbs=create;update,versions=v1,name=vclusterclient.dex.betssongroup.com,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &ClusterClient{}
+
+// ValidateCreate implements webhook.Validator
+func (r *ClusterClient) ValidateCreate() error {
+	clusterclientlog.V(1).Info("validate create", "name", r.Name)
+	return validateClient(context.Background(), "ClusterClient", r, nil)
+}
+
+// ValidateUpdate implements webhook.Validator, updates which leave the spec unchanged
+// like finalizer removal are always allowed.
+func (r *ClusterClient) ValidateUpdate(old runtime.Object) error {
+	clusterclientlog.V(1).Info("validate update", "name", r.Name)
+	oldClient := old.(*ClusterClient)
+	if r.DeletionTimestamp != nil || reflect.DeepEqual(r.Spec, oldClient.Spec) {
+		return nil
+	}
+	return validateClient(context.Background(), "ClusterClient", r, oldClient)
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *ClusterClient) ValidateDelete() error {
+	return nil
+}
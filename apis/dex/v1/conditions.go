@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types
+const (
+	// ConditionReady is true when the client exists in Dex and can be used, when
+	// the operator has a connection to a DexServer, or when an ALBAuth configured
+	// its Ingress
+	ConditionReady = "Ready"
+	// ConditionSynced is true when Dex has the latest spec of the client
+	ConditionSynced = "Synced"
+	// ConditionSecretResolved is true when the client secret could be read
+	ConditionSecretResolved = "SecretResolved"
+	// ConditionDegraded is true when a ready client could not be brought in line with its spec
+	ConditionDegraded = "Degraded"
+	// ConditionDrifted is true when the live Dex client diverged from the spec and was corrected
+	ConditionDrifted = "Drifted"
+	// ConditionDexAvailable is false while the dex server of the client can not be reached
+	ConditionDexAvailable = "DexAvailable"
+	// ConditionClientReady is true when the Client of an ALBAuth is active
+	ConditionClientReady = "ClientReady"
+	// ConditionIngressConfigured is true when the Ingress of an ALBAuth has its
+	// authentication annotations
+	ConditionIngressConfigured = "IngressConfigured"
+)
+
+// Condition reasons, they are part of the API and must not be changed
+const (
+	ReasonReady                     = "Ready"
+	ReasonCreating                  = "Creating"
+	ReasonDeleting                  = "Deleting"
+	ReasonAsExpected                = "AsExpected"
+	ReasonCreated                   = "Created"
+	ReasonAdopted                   = "Adopted"
+	ReasonUpdated                   = "Updated"
+	ReasonRecreated                 = "Recreated"
+	ReasonCreateFailed              = "CreateFailed"
+	ReasonUpdateFailed              = "UpdateFailed"
+	ReasonRecreateFailed            = "RecreateFailed"
+	ReasonRotationFailed            = "RotationFailed"
+	ReasonImmutableFieldChanged     = "ImmutableFieldChanged"
+	ReasonClientIDInvalid           = "ClientIDInvalid"
+	ReasonSecretResolved            = "SecretResolved"
+	ReasonSecretNotFound            = "SecretNotFound"
+	ReasonSecretInvalid             = "SecretInvalid"
+	ReasonInSync                    = "InSync"
+	ReasonDriftCorrected            = "DriftCorrected"
+	ReasonDriftCorrectionFailed     = "DriftCorrectionFailed"
+	ReasonClientMissing             = "ClientMissing"
+	ReasonDriftCheckFailed          = "DriftCheckFailed"
+	ReasonDriftDetectionUnsupported = "DriftDetectionUnsupported"
+	ReasonDexServerUnavailable      = "DexServerUnavailable"
+	ReasonDexServerChanged          = "DexServerChanged"
+	ReasonConfigured                = "Configured"
+	ReasonConnectionFailed          = "ConnectionFailed"
+	ReasonInUse                     = "InUse"
+	ReasonNegotiationFailed         = "NegotiationFailed"
+	ReasonDexAvailable              = "DexAvailable"
+	ReasonDexUnavailable            = "DexUnavailable"
+	ReasonWaitingForDex             = "WaitingForDex"
+	ReasonClientActive              = "ClientActive"
+	ReasonClientNotFound            = "ClientNotFound"
+	ReasonWaitingForClient          = "WaitingForClient"
+	ReasonIngressNotFound           = "IngressNotFound"
+	ReasonNotALBIngress             = "NotALBIngress"
+)
+
+// metav1.Condition is not available in the apimachinery version in use, Condition
+// has the same schema so it can be replaced without changing the CRDs.
+
+// Condition contains details for one aspect of the current state of a resource
+type Condition struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Type of condition in CamelCase
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Enum=True;False;Unknown
+
+	// Status of the condition, one of True, False, Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// +optional
+
+	// ObservedGeneration is the generation the condition was set based upon
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// Reason is a programmatic identifier in CamelCase for the last transition
+	Reason string `json:"reason"`
+
+	// +optional
+
+	// Message is a human readable message with details about the transition
+	Message string `json:"message,omitempty"`
+}
+
+// SetCondition adds or updates a condition, the transition time is only changed
+// when the status of the condition changes.
+func SetCondition(conditions *[]Condition, condition Condition) {
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
+
+// FindCondition returns the condition of the given type or nil
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns true when the condition of the given type has status True
+func IsConditionTrue(conditions []Condition, conditionType string) bool {
+	condition := FindCondition(conditions, conditionType)
+	return condition != nil && condition.Status == metav1.ConditionTrue
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Keys of the TLS Secret of a DexServer, as written by cert-manager
+const (
+	DexServerCAKey   = "ca.crt"
+	DexServerCertKey = "tls.crt"
+	DexServerKeyKey  = "tls.key"
+)
+
+// DexServerSpec defines the desired state of DexServer
+type DexServerSpec struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Host and port of the gRPC API of Dex
+	GRPC string `json:"grpc"`
+
+	// Secret holding the CA certificate, the client certificate and its key for the
+	// gRPC API under the keys ca.crt, tls.crt and tls.key
+	TLSSecretRef TLSSecretReference `json:"tlsSecretRef"`
+
+	// +optional
+
+	// Issuer URL of the Dex instance
+	Issuer string `json:"issuer,omitempty"`
+}
+
+// TLSSecretReference selects a Secret of type kubernetes.io/tls
+type TLSSecretReference struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Name of the Secret
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:MinLength=1
+
+	// Namespace of the Secret
+	Namespace string `json:"namespace"`
+}
+
+// DexServerStatus defines the observed state of DexServer
+type DexServerStatus struct {
+	// +optional
+
+	// The generation of the spec the connection was built from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// +optional
+
+	// Version of the Dex server
+	Version string `json:"version,omitempty"`
+
+	// +optional
+
+	// API level of the Dex server, it is increased when RPCs are added to the API
+	APILevel *int32 `json:"apiLevel,omitempty"`
+
+	// +optional
+
+	// Optional RPCs the Dex server does not serve, the features using them are
+	// disabled for its clients
+	UnsupportedCapabilities []string `json:"unsupportedCapabilities,omitempty"`
+
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+
+	// Conditions of the DexServer
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="gRPC",type=string,JSONPath=`.spec.grpc`
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.spec.issuer`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// DexServer is the Schema for the dexservers API, a Dex instance clients can be
+// created in
+type DexServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DexServerSpec   `json:"spec,omitempty"`
+	Status DexServerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DexServerList contains a list of DexServer
+type DexServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DexServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DexServer{}, &DexServerList{})
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Client webhook", func() {
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(AddToScheme(scheme)).To(Succeed())
+		peer := &Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-b"},
+			Status:     ClientStatus{ClientID: "web"},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "oidc", Namespace: "team-a"},
+			Data:       map[string][]byte{"short": []byte("abc")},
+		}
+		webhookClient = fake.NewFakeClientWithScheme(scheme, peer, secret)
+	})
+
+	newClient := func() *Client {
+		return &Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"},
+			Spec: ClientSpec{
+				SecretGeneration: &SecretGeneration{},
+				RedirectURIs:     []string{"https://grafana.example.com/login/generic_oauth"},
+			},
+		}
+	}
+
+	It("should default the name and policies", func() {
+		c := newClient()
+		c.Default()
+		Expect(c.Spec.Name).To(Equal("grafana"))
+		Expect(c.Spec.AdoptionPolicy).To(Equal(AdoptionPolicyNever))
+		Expect(c.Spec.UpdateStrategy).To(Equal(UpdateStrategyRecreate))
+		Expect(c.Spec.SecretGeneration.Length).To(Equal(DefaultSecretLength))
+	})
+
+	It("should accept a valid client", func() {
+		c := newClient()
+		c.Spec.TrustedPeers = []string{"web"}
+		Expect(c.ValidateCreate()).To(Succeed())
+	})
+
+	It("should reject empty and relative redirect URIs", func() {
+		c := newClient()
+		c.Spec.RedirectURIs = []string{"", "/callback"}
+		err := c.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.redirectURIs[0]"))
+		Expect(err.Error()).To(ContainSubstring("spec.redirectURIs[1]"))
+	})
+
+	It("should only require a secret for confidential clients", func() {
+		c := newClient()
+		c.Spec.SecretGeneration = nil
+		Expect(c.ValidateCreate()).NotTo(Succeed())
+		c.Spec.Public = true
+		Expect(c.ValidateCreate()).To(Succeed())
+	})
+
+	It("should reject short secrets without revealing them", func() {
+		c := newClient()
+		c.Spec.SecretGeneration = nil
+		c.Spec.Secret = "tooshort"
+		err := c.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("tooshort"))
+
+		c.Spec.Secret = ""
+		c.Spec.SecretRef = &SecretReference{Name: "oidc", Key: "short"}
+		Expect(c.ValidateCreate()).NotTo(Succeed())
+	})
+
+	It("should reject unknown trusted peers", func() {
+		c := newClient()
+		c.Spec.TrustedPeers = []string{"unknown"}
+		Expect(c.ValidateCreate()).NotTo(Succeed())
+	})
+
+	It("should allow removing finalizers of invalid clients", func() {
+		old := newClient()
+		old.Spec.TrustedPeers = []string{"deleted"}
+		c := old.DeepCopy()
+		c.Finalizers = nil
+		Expect(c.ValidateUpdate(old)).To(Succeed())
+	})
+
+	It("should reject changing the dex server of created clients", func() {
+		old := newClient()
+		old.Status.ClientID = "grafana"
+		c := old.DeepCopy()
+		c.Spec.DexServerRef = &DexServerReference{Name: "partners"}
+		Expect(c.ValidateUpdate(old)).NotTo(Succeed())
+		old.Status.ClientID = ""
+		Expect(c.ValidateUpdate(old)).To(Succeed())
+	})
+
+	It("should require secret namespaces for cluster clients", func() {
+		c := &ClusterClient{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubectl"},
+			Spec: ClientSpec{
+				SecretGeneration: &SecretGeneration{},
+				RedirectURIs:     []string{"http://localhost:8000"},
+				TrustedPeers:     []string{"web"},
+			},
+		}
+		c.Default()
+		Expect(c.Spec.Name).To(Equal("kubectl"))
+		Expect(c.ValidateCreate()).NotTo(Succeed())
+		c.Spec.SecretGeneration.Namespace = "dex"
+		Expect(c.ValidateCreate()).To(Succeed())
+	})
+})
+
+var _ = Describe("ALBAuth webhook", func() {
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(AddToScheme(scheme)).To(Succeed())
+		ingress := &extensionsv1beta1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"}}
+		dexClient := &Client{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"}}
+		webhookClient = fake.NewFakeClientWithScheme(scheme, ingress, dexClient)
+	})
+
+	newALBAuth := func() *ALBAuth {
+		return &ALBAuth{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"},
+			Spec:       ALBAuthSpec{Ingress: "grafana", Client: "grafana", Issuer: "https://dex.example.com"},
+		}
+	}
+
+	It("should accept a valid ALBAuth", func() {
+		Expect(newALBAuth().ValidateCreate()).To(Succeed())
+	})
+
+	It("should reject missing resources and insecure issuers", func() {
+		a := newALBAuth()
+		a.Spec.Ingress = "missing"
+		a.Spec.Client = "missing"
+		a.Spec.Issuer = "http://dex.example.com"
+		err := a.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.ingress"))
+		Expect(err.Error()).To(ContainSubstring("spec.client"))
+		Expect(err.Error()).To(ContainSubstring("spec.issuer"))
+	})
+})
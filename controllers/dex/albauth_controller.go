@@ -18,32 +18,59 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 
 	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// errSecretNotAccepted is returned when the resolved client secret was not pushed to
+// dex yet and there are no earlier credentials to keep
+var errSecretNotAccepted = errors.New("the client secret was not accepted by dex yet")
+
+const (
+	// albAuthIngressIndexKey indexes ALBAuths by the name of their Ingress
+	albAuthIngressIndexKey = "spec.ingress"
+	// albAuthClientIndexKey indexes ALBAuths by the name of their Client
+	albAuthClientIndexKey = "spec.client"
 )
 
 // ALBAuthReconciler reconciles a ALBAuth object
 type ALBAuthReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// SecretHashKey keys the hashes of client secrets, it must be the key of the
+	// ClientReconciler to compare secrets with the status of Clients
+	SecretHashKey []byte
 }
 
 // +kubebuilder:rbac:groups=dex.betssongroup.com,resources=albauths,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dex.betssongroup.com,resources=albauths/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=create;list;watch;delete
-// +kubebuilder:rbac:groups="extensions",resources=ingresses,verbs=list;watch;update;patch
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clients,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update;list;watch;delete
+// +kubebuilder:rbac:groups="extensions",resources=ingresses,verbs=get;list;watch;update;patch
 
-// Reconcile reconciles ALB oidc
+// Reconcile reconciles ALB oidc. The Ingress is configured once the Client is
+// active, and its annotations are applied again whenever they drift.
 func (r *ALBAuthReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
 	log := r.Log.WithValues("albauth", req.NamespacedName)
@@ -63,127 +90,265 @@ func (r *ALBAuthReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		if !containsString(dexv1ALBAuth.ObjectMeta.Finalizers, albFinalizer) {
 			// append our finalizer
 			log.Info("Adding alb finalizer")
+			patch := client.MergeFrom(dexv1ALBAuth.DeepCopy())
 			dexv1ALBAuth.ObjectMeta.Finalizers = append(dexv1ALBAuth.ObjectMeta.Finalizers, albFinalizer)
-			if err := r.Update(ctx, dexv1ALBAuth); err != nil {
+			if err := r.Patch(ctx, dexv1ALBAuth, patch); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
 	} else {
 		if containsString(dexv1ALBAuth.ObjectMeta.Finalizers, albFinalizer) {
-			dexv1ALBAuth.Status.State = dexv1.PhaseDeleting
+			return ctrl.Result{}, r.finalizeALBAuth(ctx, log, dexv1ALBAuth, albFinalizer)
 		}
-
+		return ctrl.Result{}, nil
 	}
 
-	// If the ALBAuth is already setup return
-	if dexv1ALBAuth.Status.State == dexv1.PhaseActive {
-		return ctrl.Result{}, nil
+	status := dexv1ALBAuth.Status.DeepCopy()
+	err := r.reconcileALBAuth(ctx, log, dexv1ALBAuth)
+	// Only write the status when it changed, the watches reconcile on every change
+	// of the Ingress, the Client and the Secret
+	summarizeALBAuthConditions(dexv1ALBAuth)
+	dexv1ALBAuth.Status.ObservedGeneration = dexv1ALBAuth.Generation
+	if !reflect.DeepEqual(status, &dexv1ALBAuth.Status) {
+		if updateErr := r.Status().Update(ctx, dexv1ALBAuth); updateErr != nil && err == nil {
+			err = updateErr
+		}
 	}
+	return ctrl.Result{}, err
+}
 
+// reconcileALBAuth configures the Ingress for the active Client and records the
+// outcome in the status. Missing objects are not an error, the watches reconcile
+// the ALBAuth once they are created or changed.
+func (r *ALBAuthReconciler) reconcileALBAuth(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth) error {
 	// Get the client
 	dexv1Client := &dexv1.Client{}
 	namespacedClientName := k8stypes.NamespacedName{
 		Name:      dexv1ALBAuth.Spec.Client,
 		Namespace: dexv1ALBAuth.Namespace,
 	}
-	if err := r.Get(ctx, namespacedClientName, dexv1Client); err != nil {
-		dexv1ALBAuth.Status.State = dexv1.PhaseNotFound
+	err := r.Get(ctx, namespacedClientName, dexv1Client)
+	if apierrors.IsNotFound(err) {
 		log.Info("Client not found", "client", dexv1ALBAuth.Spec.Client)
-		if err := r.Update(ctx, dexv1ALBAuth); err != nil {
-			return ctrl.Result{}, err
-		}
+		dexv1ALBAuth.Status.State = dexv1.PhaseNotFound
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionFalse, dexv1.ReasonClientNotFound,
+			fmt.Sprintf("client %s not found", dexv1ALBAuth.Spec.Client))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// The Ingress is left alone until dex has the client
+	if state := dexv1Client.Status.State; state != dexv1.PhaseActive && state != dexv1.PhaseActiveDegraded {
+		log.V(1).Info("Waiting for client", "client", dexv1Client.Name, "state", state)
+		dexv1ALBAuth.Status.State = dexv1.PhaseCreating
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionFalse, dexv1.ReasonWaitingForClient,
+			fmt.Sprintf("client %s is not active yet", dexv1Client.Name))
+		return nil
 	}
+	setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionTrue, dexv1.ReasonClientActive, "")
 
 	// Reconcile the secret
-	secret, err := r.reconcileSecret(ctx, dexv1ALBAuth, dexv1Client)
+	secret, err := r.reconcileSecret(ctx, log, dexv1ALBAuth, dexv1Client)
+	if errors.Is(err, errSecretNotAccepted) {
+		log.V(1).Info("Waiting for the client secret to be pushed to dex", "client", dexv1Client.Name)
+		dexv1ALBAuth.Status.State = dexv1.PhaseCreating
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionFalse, dexv1.ReasonWaitingForClient,
+			fmt.Sprintf("client %s: %s", dexv1Client.Name, err.Error()))
+		return nil
+	}
 	if err != nil {
 		log.Error(err, "unable to reconcile secret", "client", dexv1Client.Name)
-		return ctrl.Result{}, err
-	}
-	// Set controller reference
-	if err := ctrl.SetControllerReference(dexv1ALBAuth, secret, r.Scheme); err != nil {
-		return ctrl.Result{}, err
+		return err
 	}
 	// Set status
 	dexv1ALBAuth.Status.Secret = corev1.ObjectReference{
-		Kind:      secret.Kind,
+		Kind:      "Secret",
 		Namespace: secret.Namespace,
 		Name:      secret.Name,
 	}
 
 	// Reconcile the ingress
-	ingress, err := r.reconcileIngress(ctx, dexv1ALBAuth, dexv1Client)
+	ingress := &extensionsv1beta1.Ingress{}
+	namespacedIngressName := k8stypes.NamespacedName{
+		Name:      dexv1ALBAuth.Spec.Ingress,
+		Namespace: dexv1ALBAuth.Namespace,
+	}
+	err = r.Get(ctx, namespacedIngressName, ingress)
+	if apierrors.IsNotFound(err) {
+		log.Info("Ingress not found", "ingress", dexv1ALBAuth.Spec.Ingress)
+		dexv1ALBAuth.Status.State = dexv1.PhaseFailed
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionIngressConfigured, metav1.ConditionFalse, dexv1.ReasonIngressNotFound,
+			fmt.Sprintf("ingress %s not found", dexv1ALBAuth.Spec.Ingress))
+		return nil
+	}
 	if err != nil {
-		log.Error(err, "unable to reconcile ingress", "client", dexv1Client.Name)
-		return ctrl.Result{}, err
+		return err
+	}
+	// check if it is an ALB ingress
+	if !isALBIngress(ingress) {
+		dexv1ALBAuth.Status.State = dexv1.PhaseFailed
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionIngressConfigured, metav1.ConditionFalse, dexv1.ReasonNotALBIngress,
+			fmt.Sprintf("ingress %s is not of class alb", ingress.Name))
+		return nil
+	}
+	if err := r.reconcileIngress(ctx, log, dexv1ALBAuth, ingress); err != nil {
+		log.Error(err, "unable to reconcile ingress", "ingress", ingress.Name)
+		dexv1ALBAuth.Status.State = dexv1.PhaseFailed
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionIngressConfigured, metav1.ConditionFalse, dexv1.ReasonUpdateFailed, err.Error())
+		return err
 	}
 
 	// Set status
 	dexv1ALBAuth.Status.Ingress = corev1.ObjectReference{
-		Kind:      ingress.Kind,
+		Kind:      "Ingress",
 		Namespace: ingress.Namespace,
 		Name:      ingress.Name,
 	}
-	if dexv1ALBAuth.Status.State == dexv1.PhaseDeleting {
-		// Remove our finalizer since we cleaned up
-		dexv1ALBAuth.ObjectMeta.Finalizers = removeString(dexv1ALBAuth.ObjectMeta.Finalizers, albFinalizer)
-	} else {
-		dexv1ALBAuth.Status.State = dexv1.PhaseActive
-	}
-	err = r.Update(ctx, dexv1ALBAuth)
+	dexv1ALBAuth.Status.State = dexv1.PhaseActive
+	setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionIngressConfigured, metav1.ConditionTrue, dexv1.ReasonConfigured, "")
+	return nil
+}
+
+// reconcileSecret writes the resolved credentials of the client to the Secret the
+// ALB reads them from. The Secret lives in the namespace of the ALBAuth and is
+// controlled by it, existing Secrets without a controller are adopted. Secrets dex
+// did not accept yet, e.g. rejected by the update strategy or while a recreate is
+// pending, are not written, the last synced credentials are kept.
+func (r *ALBAuthReconciler) reconcileSecret(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth, dexv1Client *dexv1.Client) (*corev1.Secret, error) {
+	clientSecret, err := resolveClientSecret(ctx, r, dexv1Client)
 	if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
 	}
-	return ctrl.Result{}, nil
-}
+	accepted := dexv1Client.Status.SecretHash == "" ||
+		secretMatches(r.SecretHashKey, dexv1Client.Status.SecretHash, clientSecret)
+	clientID := ownedClientID(dexv1Client)
+	if clientID == "" {
+		clientID = dexv1Client.Name
+	}
+	hash := albCredentialsHash(clientID, clientSecret)
 
-func (r *ALBAuthReconciler) reconcileSecret(ctx context.Context, dexv1ALBAuth *dexv1.ALBAuth, dexv1Client *dexv1.Client) (*corev1.Secret, error) {
-	// Check if secret exists and is up to date
+	secret := &corev1.Secret{}
 	namespacedName := k8stypes.NamespacedName{
-		Name:      fmt.Sprintf("alb-secret-%s", dexv1Client.Name),
+		Name:      albSecretName(dexv1ALBAuth),
 		Namespace: dexv1ALBAuth.Namespace,
 	}
-	secret := &corev1.Secret{}
-	if err := r.Get(ctx, namespacedName, secret); err != nil {
+	err = r.Get(ctx, namespacedName, secret)
+	if apierrors.IsNotFound(err) && !accepted {
+		return nil, errSecretNotAccepted
+	}
+	if apierrors.IsNotFound(err) {
 		// No secret found, create it
-		if client.IgnoreNotFound(err) == nil {
-			newSecret := &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels:      make(map[string]string),
-					Annotations: make(map[string]string),
-					Name:        fmt.Sprintf("alb-secret-%s", dexv1Client.Name),
-					Namespace:   dexv1Client.Namespace,
-				},
-				StringData: map[string]string{
-					"clientId":     dexv1Client.Name,
-					"clientSecret": dexv1Client.Spec.Secret,
-				},
-			}
-			if err := r.Create(ctx, newSecret); err != nil {
-				return nil, err
-			}
-			return newSecret, nil
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        namespacedName.Name,
+				Namespace:   namespacedName.Namespace,
+				Annotations: map[string]string{dexv1.ALBSecretHashAnnotation: hash},
+			},
+			Data: map[string][]byte{
+				"clientId":     []byte(clientID),
+				"clientSecret": []byte(clientSecret),
+			},
 		}
+		// Set controller reference
+		if err := ctrl.SetControllerReference(dexv1ALBAuth, secret, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return nil, err
+		}
+		log.Info("Created secret", "secret", secret.Name)
+		r.Recorder.Eventf(dexv1ALBAuth, "Normal", "SecretCreated", "secret %s: credentials of client %q", secret.Name, clientID)
+		return secret, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !accepted {
+		log.V(1).Info("Keeping the last synced credentials until dex accepts the client secret", "secret", secret.Name)
+		return secret, nil
+	}
+	// The hash is compared with the data too, so edits of the Secret are reverted
+	if metav1.IsControlledBy(secret, dexv1ALBAuth) && secret.Annotations[dexv1.ALBSecretHashAnnotation] == hash &&
+		albCredentialsHash(string(secret.Data["clientId"]), string(secret.Data["clientSecret"])) == hash {
+		return secret, nil
+	}
+	if err := ctrl.SetControllerReference(dexv1ALBAuth, secret, r.Scheme); err != nil {
+		return nil, err
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[dexv1.ALBSecretHashAnnotation] = hash
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["clientId"] = []byte(clientID)
+	secret.Data["clientSecret"] = []byte(clientSecret)
+	if err := r.Update(ctx, secret); err != nil {
 		return nil, err
 	}
+	log.Info("Updated secret", "secret", secret.Name)
+	r.Recorder.Eventf(dexv1ALBAuth, "Normal", "SecretUpdated", "secret %s: credentials of client %q", secret.Name, clientID)
 	return secret, nil
 }
 
-func (r *ALBAuthReconciler) reconcileIngress(ctx context.Context, dexv1ALBAuth *dexv1.ALBAuth, dexv1Client *dexv1.Client) (*extensionsv1beta1.Ingress, error) {
-	log := r.Log.WithValues("albauth", dexv1ALBAuth.Name)
-	ingress := &extensionsv1beta1.Ingress{}
-	namespacedIngressName := k8stypes.NamespacedName{
-		Name:      dexv1ALBAuth.Spec.Ingress,
-		Namespace: dexv1ALBAuth.Namespace,
+// albCredentialsHash returns the hex encoded SHA-256 of the client credentials
+func albCredentialsHash(clientID, clientSecret string) string {
+	sum := sha256.New()
+	for _, value := range []string{clientID, clientSecret} {
+		sum.Write([]byte(value))
+		sum.Write([]byte{0})
 	}
-	if err := r.Get(ctx, namespacedIngressName, ingress); err != nil {
-		log.Error(err, "unable to find ingress", "client", dexv1Client.Name)
-		return nil, err
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// reconcileIngress applies the authentication annotations to the Ingress when any
+// of them is missing or was changed. The values overwritten the first time are
+// recorded on the Ingress, later changes are drift and not recorded.
+func (r *ALBAuthReconciler) reconcileIngress(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth, ingress *extensionsv1beta1.Ingress) error {
+	neededAnnotations := albAuthAnnotations(dexv1ALBAuth)
+	drifted := false
+	for k, v := range neededAnnotations {
+		if !mapContains(ingress.GetAnnotations(), k, v) {
+			drifted = true
+		}
 	}
-	// check if it is an ALB ingress
-	if !mapContains(ingress.Annotations, "kubernetes.io/ingress.class", "alb") {
-		return nil, nil
+	if !drifted {
+		return nil
 	}
+	patch := client.MergeFrom(ingress.DeepCopy())
+	annotations := ingress.GetAnnotations()
+	if _, ok := annotations[dexv1.OverwrittenAnnotationsAnnotation]; !ok {
+		overwritten := map[string]string{}
+		for k := range neededAnnotations {
+			if v, ok := annotations[k]; ok {
+				overwritten[k] = v
+			}
+		}
+		data, err := json.Marshal(overwritten)
+		if err != nil {
+			return err
+		}
+		annotations[dexv1.OverwrittenAnnotationsAnnotation] = string(data)
+	}
+	annotations, err := makeAnnotations(annotations, neededAnnotations, false)
+	if err != nil {
+		return err
+	}
+	ingress.SetAnnotations(annotations)
+	if err := r.Patch(ctx, ingress, patch); err != nil {
+		return err
+	}
+	log.Info("Applied auth annotations", "ingress", ingress.Name)
+	r.Recorder.Eventf(dexv1ALBAuth, "Normal", "IngressConfigured", "ingress %s: applied the oidc annotations", ingress.Name)
+	return nil
+}
+
+// albAuthAnnotations returns the annotations the ALB ingress controller reads the
+// oidc configuration from
+func albAuthAnnotations(dexv1ALBAuth *dexv1.ALBAuth) map[string]string {
 	// alb.ingress.kubernetes.io/auth-type: oidc
 	// alb.ingress.kubernetes.io/auth-idp-oidc: '{"Issuer":"https://albingress.auth0.com/","AuthorizationEndpoint":"https://albingress.auth0.com/authorize","TokenEndpoint":"https://albingress.auth0.com/oauth/token","UserInfoEndpoint":"https://albingress.auth0.com/userinfo","SecretName":"odic-secret"}'
 	authIdpOidc := fmt.Sprintf(
@@ -192,44 +357,116 @@ func (r *ALBAuthReconciler) reconcileIngress(ctx context.Context, dexv1ALBAuth *
 		fmt.Sprintf("%s/auth", dexv1ALBAuth.Spec.Issuer),
 		fmt.Sprintf("%s/token", dexv1ALBAuth.Spec.Issuer),
 		fmt.Sprintf("%s/userinfo", dexv1ALBAuth.Spec.Issuer),
-		fmt.Sprintf("alb-secret-%s", dexv1Client.Name),
+		albSecretName(dexv1ALBAuth),
 	)
-	neededAnnotations := map[string]string{
+	return map[string]string{
 		"alb.ingress.kubernetes.io/auth-idp-oidc":                   authIdpOidc,
 		"alb.ingress.kubernetes.io/auth-type":                       "oidc",
 		"alb.ingress.kubernetes.io/auth-on-unauthenticated-request": "authenticate",
 	}
-	// The object is being deleted, remove annotations
+}
 
-	currentAnnotations := ingress.GetAnnotations()
-	if dexv1ALBAuth.ObjectMeta.DeletionTimestamp.IsZero() {
-		annotations, err := makeAnnotations(currentAnnotations, neededAnnotations, false)
-		if err != nil {
-			return nil, err
-		}
-		ingress.SetAnnotations(annotations)
-		// Update the objects
-		err = r.Update(ctx, ingress)
-		if err != nil {
-			return nil, err
+// albSecretName returns the name of the Secret with the client credentials, it is
+// named after the ALBAuth so ALBAuths sharing a Client each control their own Secret
+func albSecretName(dexv1ALBAuth *dexv1.ALBAuth) string {
+	return fmt.Sprintf("alb-secret-%s", dexv1ALBAuth.Name)
+}
+
+// legacyALBSecretName returns the name of the Secret earlier versions created for the
+// Client of the ALBAuth
+func legacyALBSecretName(dexv1ALBAuth *dexv1.ALBAuth) string {
+	return fmt.Sprintf("alb-secret-%s", dexv1ALBAuth.Spec.Client)
+}
+
+// isALBIngress returns true when the Ingress is served by the ALB ingress controller
+func isALBIngress(ingress *extensionsv1beta1.Ingress) bool {
+	return mapContains(ingress.Annotations, "kubernetes.io/ingress.class", "alb")
+}
+
+// setALBAuthCondition sets a condition observed at the current generation of the ALBAuth
+func setALBAuthCondition(dexv1ALBAuth *dexv1.ALBAuth, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	dexv1.SetCondition(&dexv1ALBAuth.Status.Conditions, dexv1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: dexv1ALBAuth.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// summarizeALBAuthConditions derives the Ready condition from the ClientReady and
+// IngressConfigured conditions
+func summarizeALBAuthConditions(dexv1ALBAuth *dexv1.ALBAuth) {
+	conditions := dexv1ALBAuth.Status.Conditions
+	for _, conditionType := range []string{dexv1.ConditionClientReady, dexv1.ConditionIngressConfigured} {
+		if c := dexv1.FindCondition(conditions, conditionType); c != nil && c.Status != metav1.ConditionTrue {
+			setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionReady, metav1.ConditionFalse, c.Reason, c.Message)
+			return
 		}
-		return ingress, nil
 	}
-	// object is being deleted
-	annotations, err := makeAnnotations(currentAnnotations, neededAnnotations, true)
-	ingress.SetAnnotations(annotations)
-	// Update the objects
-	err = r.Update(ctx, ingress)
-	if err != nil {
-		return nil, err
+	if dexv1.IsConditionTrue(conditions, dexv1.ConditionIngressConfigured) {
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionReady, metav1.ConditionTrue, dexv1.ReasonReady, "")
+		return
+	}
+	setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionReady, metav1.ConditionFalse, dexv1.ReasonCreating, "")
+}
+
+// indexALBAuthIngress indexes ALBAuths by the name of their Ingress
+func indexALBAuthIngress(o runtime.Object) []string {
+	return []string{o.(*dexv1.ALBAuth).Spec.Ingress}
+}
+
+// indexALBAuthClient indexes ALBAuths by the name of their Client
+func indexALBAuthClient(o runtime.Object) []string {
+	return []string{o.(*dexv1.ALBAuth).Spec.Client}
+}
+
+// albAuthsForIngress maps an Ingress to the ALBAuths configuring it
+func (r *ALBAuthReconciler) albAuthsForIngress(o handler.MapObject) []reconcile.Request {
+	return r.albAuthsFor(o, albAuthIngressIndexKey)
+}
+
+// albAuthsForClient maps a Client to the ALBAuths authenticating with it
+func (r *ALBAuthReconciler) albAuthsForClient(o handler.MapObject) []reconcile.Request {
+	return r.albAuthsFor(o, albAuthClientIndexKey)
+}
+
+// albAuthsFor returns the ALBAuths in the namespace of the object which name it
+// in the indexed field
+func (r *ALBAuthReconciler) albAuthsFor(o handler.MapObject, indexKey string) []reconcile.Request {
+	albAuths := &dexv1.ALBAuthList{}
+	if err := r.List(context.Background(), albAuths, client.InNamespace(o.Meta.GetNamespace()),
+		client.MatchingFields{indexKey: o.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list albauths", "field", indexKey, "name", o.Meta.GetName())
+		return nil
 	}
-	return ingress, nil
+	requests := make([]reconcile.Request, 0, len(albAuths.Items))
+	for _, item := range albAuths.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name, Namespace: item.Namespace},
+		})
+	}
+	return requests
 }
 
 // SetupWithManager sets up the mananager
 func (r *ALBAuthReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ALBAuth{}, albAuthIngressIndexKey, indexALBAuthIngress); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ALBAuth{}, albAuthClientIndexKey, indexALBAuthClient); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dexv1.ALBAuth{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &extensionsv1beta1.Ingress{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.albAuthsForIngress),
+		}).
+		Watches(&source.Kind{Type: &dexv1.Client{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.albAuthsForClient),
+		}).
+		WithEventFilter(ignoreALBAuthStatusUpdates{}).
 		Complete(r)
 }
 
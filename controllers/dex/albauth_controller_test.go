@@ -0,0 +1,341 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Context("ALBAuth inside of a new namespace", func() {
+	defer GinkgoRecover()
+	ctx := context.TODO()
+	ns := SetupTest(ctx)
+
+	newIngress := func(name string) *extensionsv1beta1.Ingress {
+		return &extensionsv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ns.Name,
+				Annotations: map[string]string{"kubernetes.io/ingress.class": "alb"},
+			},
+			Spec: extensionsv1beta1.IngressSpec{
+				Backend: &extensionsv1beta1.IngressBackend{ServiceName: name, ServicePort: intstr.FromInt(80)},
+			},
+		}
+	}
+	newALBAuth := func(name string) *dexv1.ALBAuth {
+		return &dexv1.ALBAuth{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns.Name},
+			Spec: dexv1.ALBAuthSpec{
+				Ingress: name,
+				Client:  name,
+				Issuer:  "https://dex.betssongroup.com",
+			},
+		}
+	}
+	readyReason := func(dexv1ALBAuth *dexv1.ALBAuth) func() string {
+		return func() string {
+			latest := &dexv1.ALBAuth{}
+			key := k8stypes.NamespacedName{Name: dexv1ALBAuth.Name, Namespace: ns.Name}
+			if err := k8sClient.Get(ctx, key, latest); err != nil {
+				return err.Error()
+			}
+			if condition := dexv1.FindCondition(latest.Status.Conditions, dexv1.ConditionReady); condition != nil {
+				return condition.Reason
+			}
+			return ""
+		}
+	}
+	authType := func(ingress *extensionsv1beta1.Ingress) func() string {
+		return func() string {
+			latest := &extensionsv1beta1.Ingress{}
+			key := k8stypes.NamespacedName{Name: ingress.Name, Namespace: ns.Name}
+			if err := k8sClient.Get(ctx, key, latest); err != nil {
+				return err.Error()
+			}
+			return latest.Annotations["alb.ingress.kubernetes.io/auth-type"]
+		}
+	}
+
+	It("should wait for the client and configure the ingress", func() {
+		ingress := newIngress("albauth")
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("albauth")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		Eventually(readyReason(dexv1ALBAuth), 10*time.Second).Should(Equal(dexv1.ReasonClientNotFound))
+		Expect(authType(ingress)()).To(BeEmpty())
+
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "albauth", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:       "xxx-xxx-xxx-xxx",
+				Name:         "ALB Client",
+				RedirectURIs: []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		Eventually(readyReason(dexv1ALBAuth), 10*time.Second).Should(Equal(dexv1.ReasonReady))
+		Expect(authType(ingress)()).To(Equal("oidc"))
+
+		secret := &corev1.Secret{}
+		key := k8stypes.NamespacedName{Name: "alb-secret-albauth", Namespace: ns.Name}
+		Expect(k8sClient.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(secret.OwnerReferences[0].Name).To(Equal(dexv1ALBAuth.Name))
+	})
+
+	It("should give ALBAuths sharing a client their own secret", func() {
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:       "xxx-xxx-xxx-xxx",
+				Name:         "ALB Client",
+				RedirectURIs: []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		for _, name := range []string{"shared-a", "shared-b"} {
+			Expect(k8sClient.Create(ctx, newIngress(name))).To(Succeed())
+			dexv1ALBAuth := newALBAuth(name)
+			dexv1ALBAuth.Spec.Client = dexv1Client.Name
+			Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		}
+		for _, name := range []string{"shared-a", "shared-b"} {
+			Eventually(readyReason(newALBAuth(name)), 10*time.Second).Should(Equal(dexv1.ReasonReady))
+			key := k8stypes.NamespacedName{Name: "alb-secret-" + name, Namespace: ns.Name}
+			Expect(k8sClient.Get(ctx, key, &corev1.Secret{})).To(Succeed())
+		}
+	})
+
+	It("should apply drifting annotations again", func() {
+		ingress := newIngress("drift")
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "drift", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:       "xxx-xxx-xxx-xxx",
+				Name:         "ALB Client",
+				RedirectURIs: []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("drift")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		Eventually(authType(ingress), 10*time.Second).Should(Equal("oidc"))
+
+		key := k8stypes.NamespacedName{Name: ingress.Name, Namespace: ns.Name}
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, key, ingress); err != nil {
+				return err
+			}
+			ingress.Annotations["alb.ingress.kubernetes.io/auth-type"] = "none"
+			return k8sClient.Update(ctx, ingress)
+		}, 10*time.Second).Should(Succeed())
+		Eventually(authType(ingress), 10*time.Second).Should(Equal("oidc"))
+	})
+
+	It("should keep the secret in sync with the client", func() {
+		ingress := newIngress("sync")
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "sync", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:       "xxx-xxx-xxx-xxx",
+				Name:         "ALB Client",
+				RedirectURIs: []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("sync")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		secretKey := k8stypes.NamespacedName{Name: "alb-secret-sync", Namespace: ns.Name}
+		clientSecret := func() string {
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, secretKey, secret); err != nil {
+				return err.Error()
+			}
+			return string(secret.Data["clientSecret"])
+		}
+		Eventually(clientSecret, 10*time.Second).Should(Equal("xxx-xxx-xxx-xxx"))
+
+		key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+				return err
+			}
+			dexv1Client.Spec.Secret = "yyy-yyy-yyy-yyy"
+			return k8sClient.Update(ctx, dexv1Client)
+		}, 10*time.Second).Should(Succeed())
+		Eventually(clientSecret, 10*time.Second).Should(Equal("yyy-yyy-yyy-yyy"))
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+		Expect(secret.Annotations).To(HaveKeyWithValue(dexv1.ALBSecretHashAnnotation,
+			albCredentialsHash(string(secret.Data["clientId"]), "yyy-yyy-yyy-yyy")))
+	})
+
+	It("should keep the secret until dex accepted the client secret", func() {
+		ingress := newIngress("rejected")
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "rejected", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:         "xxx-xxx-xxx-xxx",
+				Name:           "ALB Client",
+				RedirectURIs:   []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+				UpdateStrategy: dexv1.UpdateStrategyReject,
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("rejected")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		secretKey := k8stypes.NamespacedName{Name: "alb-secret-rejected", Namespace: ns.Name}
+		clientSecret := func() string {
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, secretKey, secret); err != nil {
+				return err.Error()
+			}
+			return string(secret.Data["clientSecret"])
+		}
+		Eventually(clientSecret, 10*time.Second).Should(Equal("xxx-xxx-xxx-xxx"))
+
+		key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+				return err
+			}
+			dexv1Client.Spec.Secret = "yyy-yyy-yyy-yyy"
+			return k8sClient.Update(ctx, dexv1Client)
+		}, 10*time.Second).Should(Succeed())
+		Consistently(clientSecret, 2*time.Second).Should(Equal("xxx-xxx-xxx-xxx"))
+	})
+
+	It("should restore the ingress and delete the secret on deletion", func() {
+		ingress := newIngress("teardown")
+		ingress.Annotations["alb.ingress.kubernetes.io/auth-type"] = "cognito"
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "teardown", Namespace: ns.Name},
+			Spec: dexv1.ClientSpec{
+				Secret:       "xxx-xxx-xxx-xxx",
+				Name:         "ALB Client",
+				RedirectURIs: []string{"https://www.betssongroup.com/oauth2/idpresponse"},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("teardown")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		Eventually(authType(ingress), 10*time.Second).Should(Equal("oidc"))
+
+		Expect(k8sClient.Delete(ctx, dexv1ALBAuth)).To(Succeed())
+		key := k8stypes.NamespacedName{Name: dexv1ALBAuth.Name, Namespace: ns.Name}
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.ALBAuth{}))
+		}, 10*time.Second).Should(BeTrue())
+		Expect(authType(ingress)()).To(Equal("cognito"))
+		latest := &extensionsv1beta1.Ingress{}
+		Expect(k8sClient.Get(ctx, k8stypes.NamespacedName{Name: ingress.Name, Namespace: ns.Name}, latest)).To(Succeed())
+		Expect(latest.Annotations).NotTo(HaveKey("alb.ingress.kubernetes.io/auth-idp-oidc"))
+		Expect(latest.Annotations).NotTo(HaveKey(dexv1.OverwrittenAnnotationsAnnotation))
+		secretKey := k8stypes.NamespacedName{Name: "alb-secret-teardown", Namespace: ns.Name}
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, secretKey, &corev1.Secret{}))).To(BeTrue())
+	})
+
+	It("should delete the secret of earlier versions with the last ALBAuth of the client", func() {
+		legacy := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "alb-secret-legacy", Namespace: ns.Name},
+			StringData: map[string]string{"clientId": "legacy", "clientSecret": "xxx-xxx-xxx-xxx"},
+		}
+		Expect(k8sClient.Create(ctx, legacy)).To(Succeed())
+		var albAuths []*dexv1.ALBAuth
+		for _, name := range []string{"legacy-a", "legacy-b"} {
+			Expect(k8sClient.Create(ctx, newIngress(name))).To(Succeed())
+			dexv1ALBAuth := newALBAuth(name)
+			dexv1ALBAuth.Spec.Client = "legacy"
+			Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+			Eventually(readyReason(dexv1ALBAuth), 10*time.Second).Should(Equal(dexv1.ReasonClientNotFound))
+			albAuths = append(albAuths, dexv1ALBAuth)
+		}
+		gone := func(dexv1ALBAuth *dexv1.ALBAuth) func() bool {
+			return func() bool {
+				key := k8stypes.NamespacedName{Name: dexv1ALBAuth.Name, Namespace: ns.Name}
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.ALBAuth{}))
+			}
+		}
+		secretKey := k8stypes.NamespacedName{Name: legacy.Name, Namespace: ns.Name}
+
+		Expect(k8sClient.Delete(ctx, albAuths[0])).To(Succeed())
+		Eventually(gone(albAuths[0]), 10*time.Second).Should(BeTrue())
+		Expect(k8sClient.Get(ctx, secretKey, &corev1.Secret{})).To(Succeed())
+
+		Expect(k8sClient.Delete(ctx, albAuths[1])).To(Succeed())
+		Eventually(gone(albAuths[1]), 10*time.Second).Should(BeTrue())
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, secretKey, &corev1.Secret{}))).To(BeTrue())
+	})
+
+	It("should release ALBAuths whose ingress is gone", func() {
+		ingress := newIngress("gone")
+		Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		dexv1ALBAuth := newALBAuth("gone")
+		Expect(k8sClient.Create(ctx, dexv1ALBAuth)).To(Succeed())
+		Eventually(readyReason(dexv1ALBAuth), 10*time.Second).Should(Equal(dexv1.ReasonClientNotFound))
+
+		Expect(k8sClient.Delete(ctx, ingress)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, dexv1ALBAuth)).To(Succeed())
+		key := k8stypes.NamespacedName{Name: dexv1ALBAuth.Name, Namespace: ns.Name}
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.ALBAuth{}))
+		}, 10*time.Second).Should(BeTrue())
+	})
+})
+
+var _ = Describe("albCredentialsHash", func() {
+	It("should change with the client ID and the secret", func() {
+		hash := albCredentialsHash("client", "secret")
+		Expect(albCredentialsHash("client", "secret")).To(Equal(hash))
+		Expect(albCredentialsHash("client2", "secret")).NotTo(Equal(hash))
+		Expect(albCredentialsHash("client", "secret2")).NotTo(Equal(hash))
+		Expect(albCredentialsHash("clients", "ecret")).NotTo(Equal(hash))
+	})
+})
+
+var _ = Describe("summarizeALBAuthConditions", func() {
+	It("should explain why the ALBAuth is not ready", func() {
+		dexv1ALBAuth := &dexv1.ALBAuth{}
+		summarizeALBAuthConditions(dexv1ALBAuth)
+		Expect(dexv1.FindCondition(dexv1ALBAuth.Status.Conditions, dexv1.ConditionReady).Reason).To(Equal(dexv1.ReasonCreating))
+
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionFalse, dexv1.ReasonWaitingForClient, "")
+		summarizeALBAuthConditions(dexv1ALBAuth)
+		Expect(dexv1.FindCondition(dexv1ALBAuth.Status.Conditions, dexv1.ConditionReady).Reason).To(Equal(dexv1.ReasonWaitingForClient))
+
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionClientReady, metav1.ConditionTrue, dexv1.ReasonClientActive, "")
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionIngressConfigured, metav1.ConditionTrue, dexv1.ReasonConfigured, "")
+		summarizeALBAuthConditions(dexv1ALBAuth)
+		Expect(dexv1.IsConditionTrue(dexv1ALBAuth.Status.Conditions, dexv1.ConditionReady)).To(BeTrue())
+	})
+})
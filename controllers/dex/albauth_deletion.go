@@ -0,0 +1,176 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+// finalizeALBAuth tears the ALBAuth down and releases the finalizer once all steps
+// succeeded: the authentication annotations are removed from the Ingress and the
+// ones they overwrote are restored, then the Secret owned by the ALBAuth and the
+// one of earlier versions are deleted. A missing Ingress or Secret counts as cleaned
+// up, failed steps are retried.
+func (r *ALBAuthReconciler) finalizeALBAuth(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth, finalizer string) error {
+	if dexv1ALBAuth.Status.State != dexv1.PhaseDeleting {
+		dexv1ALBAuth.Status.State = dexv1.PhaseDeleting
+		setALBAuthCondition(dexv1ALBAuth, dexv1.ConditionReady, metav1.ConditionFalse, dexv1.ReasonDeleting, "")
+		if err := r.Status().Update(ctx, dexv1ALBAuth); err != nil {
+			return err
+		}
+	}
+
+	if err := r.restoreIngress(ctx, log, dexv1ALBAuth); err != nil {
+		log.Error(err, "unable to restore ingress", "ingress", dexv1ALBAuth.Spec.Ingress)
+		r.Recorder.Eventf(dexv1ALBAuth, "Warning", "TeardownFailed", "ingress %s: %s", dexv1ALBAuth.Spec.Ingress, err.Error())
+		return err
+	}
+	if err := r.deleteSecret(ctx, log, dexv1ALBAuth); err != nil {
+		log.Error(err, "unable to delete secret")
+		r.Recorder.Eventf(dexv1ALBAuth, "Warning", "TeardownFailed", "secret %s: %s", albSecretName(dexv1ALBAuth), err.Error())
+		return err
+	}
+	if err := r.deleteLegacySecret(ctx, log, dexv1ALBAuth); err != nil {
+		log.Error(err, "unable to delete legacy secret")
+		r.Recorder.Eventf(dexv1ALBAuth, "Warning", "TeardownFailed", "secret %s: %s", legacyALBSecretName(dexv1ALBAuth), err.Error())
+		return err
+	}
+
+	// Remove our finalizer since we cleaned up
+	patch := client.MergeFrom(dexv1ALBAuth.DeepCopy())
+	dexv1ALBAuth.ObjectMeta.Finalizers = removeString(dexv1ALBAuth.ObjectMeta.Finalizers, finalizer)
+	return r.Patch(ctx, dexv1ALBAuth, patch)
+}
+
+// restoreIngress removes the authentication annotations which still have the
+// values of the ALBAuth and restores the values they overwrote
+func (r *ALBAuthReconciler) restoreIngress(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth) error {
+	ingress := &extensionsv1beta1.Ingress{}
+	namespacedIngressName := k8stypes.NamespacedName{
+		Name:      dexv1ALBAuth.Spec.Ingress,
+		Namespace: dexv1ALBAuth.Namespace,
+	}
+	err := r.Get(ctx, namespacedIngressName, ingress)
+	if apierrors.IsNotFound(err) {
+		log.Info("Ingress already gone", "ingress", dexv1ALBAuth.Spec.Ingress)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !isALBIngress(ingress) {
+		return nil
+	}
+
+	// Patch the annotations only, so concurrent changes to the Ingress are kept
+	patch := client.MergeFrom(ingress.DeepCopy())
+	annotations, err := makeAnnotations(ingress.GetAnnotations(), albAuthAnnotations(dexv1ALBAuth), true)
+	if err != nil {
+		return err
+	}
+	if data, ok := annotations[dexv1.OverwrittenAnnotationsAnnotation]; ok {
+		overwritten := map[string]string{}
+		if err := json.Unmarshal([]byte(data), &overwritten); err != nil {
+			// A broken record must not block the deletion, the annotations are removed
+			log.Error(err, "unable to read the overwritten annotations", "ingress", ingress.Name)
+		}
+		// Annotations changed since they were applied are kept
+		for k, v := range overwritten {
+			if _, ok := annotations[k]; !ok {
+				annotations[k] = v
+			}
+		}
+		delete(annotations, dexv1.OverwrittenAnnotationsAnnotation)
+	}
+	ingress.SetAnnotations(annotations)
+	if err := r.Patch(ctx, ingress, patch); err != nil {
+		return err
+	}
+	log.Info("Removed auth annotations", "ingress", ingress.Name)
+	return nil
+}
+
+// deleteSecret deletes the Secret with the client credentials, Secrets not
+// controlled by the ALBAuth are left alone
+func (r *ALBAuthReconciler) deleteSecret(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth) error {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, k8stypes.NamespacedName{Name: albSecretName(dexv1ALBAuth), Namespace: dexv1ALBAuth.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(secret, dexv1ALBAuth) {
+		log.Info("Leaving secret not owned by the albauth", "secret", secret.Name)
+		return nil
+	}
+	if err := r.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.Info("Deleted secret", "secret", secret.Name)
+	return nil
+}
+
+// deleteLegacySecret deletes the Secret earlier versions created for the Client of
+// the ALBAuth, named alb-secret-<client> and without an owner. It is shared by the
+// ALBAuths of the Client, so it is only deleted with the last of them and when it
+// holds the credentials of the Client.
+func (r *ALBAuthReconciler) deleteLegacySecret(ctx context.Context, log logr.Logger, dexv1ALBAuth *dexv1.ALBAuth) error {
+	name := legacyALBSecretName(dexv1ALBAuth)
+	if dexv1ALBAuth.Spec.Client == "" || name == albSecretName(dexv1ALBAuth) {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, k8stypes.NamespacedName{Name: name, Namespace: dexv1ALBAuth.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if metav1.GetControllerOf(secret) != nil || string(secret.Data["clientId"]) != dexv1ALBAuth.Spec.Client {
+		log.Info("Leaving secret not created for the client", "secret", secret.Name)
+		return nil
+	}
+	albAuths := &dexv1.ALBAuthList{}
+	if err := r.List(ctx, albAuths, client.InNamespace(dexv1ALBAuth.Namespace),
+		client.MatchingFields{albAuthClientIndexKey: dexv1ALBAuth.Spec.Client}); err != nil {
+		return err
+	}
+	for _, item := range albAuths.Items {
+		if item.UID != dexv1ALBAuth.UID {
+			return nil
+		}
+	}
+	if err := r.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.Info("Deleted legacy secret", "secret", secret.Name)
+	return nil
+}
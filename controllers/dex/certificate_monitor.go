@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+var (
+	certificateExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dex_client_certificate_expiry_timestamp_seconds",
+			Help: "Expiry of the client certificate used for dex, the dexserver is empty for the default instance",
+		},
+		[]string{"dexserver"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(certificateExpiry)
+}
+
+// CertificateMonitor reports the expiry of the client certificates of the
+// connections to dex. Certificates are read again when they change, so renewed
+// certificates are reported with a CertificateRenewed event.
+type CertificateMonitor struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	// DexClients is the pool shared with the client reconcilers
+	DexClients *dexapi.Pool
+	// Interval is the interval at which the certificates are checked
+	Interval time.Duration
+	// WarningPeriod is the time before the expiry of a certificate from which on
+	// warnings are recorded
+	WarningPeriod time.Duration
+	// Pod is the object events about the default instance are recorded for, usually
+	// the pod of the operator, they are only logged when it is nil
+	Pod *corev1.ObjectReference
+
+	// serials are the serial numbers of the certificates last seen by server
+	serials map[string]string
+}
+
+// Start implements manager.Runnable, it checks the certificates until stop is closed
+func (m *CertificateMonitor) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		m.check(context.Background())
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check updates the expiry metric and records events for new and expiring certificates
+func (m *CertificateMonitor) check(ctx context.Context) {
+	if m.serials == nil {
+		m.serials = map[string]string{}
+	}
+	clients := m.DexClients.Clients()
+	for server := range m.serials {
+		if _, ok := clients[server]; !ok {
+			delete(m.serials, server)
+			certificateExpiry.DeleteLabelValues(server)
+		}
+	}
+	for server, dex := range clients {
+		log := m.Log.WithValues("dexserver", server)
+		cert, err := dex.Certificate()
+		if err != nil {
+			log.Error(err, "unable to read the dex client certificate")
+			continue
+		}
+		certificateExpiry.WithLabelValues(server).Set(float64(cert.NotAfter.Unix()))
+
+		object := m.eventObject(ctx, server)
+		expiry := cert.NotAfter.Format(time.RFC3339)
+		if previous, ok := m.serials[server]; !ok || previous != cert.SerialNumber {
+			m.serials[server] = cert.SerialNumber
+			reason := "CertificateLoaded"
+			if ok {
+				reason = "CertificateRenewed"
+			}
+			log.Info("Dex client certificate loaded", "subject", cert.Subject, "notAfter", expiry)
+			m.event(object, "Normal", reason, "dex client certificate %s is valid until %s", cert.Subject, expiry)
+		}
+
+		remaining := time.Until(cert.NotAfter)
+		switch {
+		case remaining <= 0:
+			log.Info("Dex client certificate expired", "subject", cert.Subject, "notAfter", expiry)
+			m.event(object, "Warning", "CertificateExpired", "dex client certificate %s expired at %s", cert.Subject, expiry)
+		case remaining < m.WarningPeriod:
+			log.Info("Dex client certificate expires soon", "subject", cert.Subject, "notAfter", expiry)
+			m.event(object, "Warning", "CertificateExpiring", "dex client certificate %s expires at %s", cert.Subject, expiry)
+		}
+	}
+}
+
+// eventObject returns the object events about the certificate of the server are
+// recorded for, the DexServer or the pod for the default instance
+func (m *CertificateMonitor) eventObject(ctx context.Context, server string) runtime.Object {
+	if server == "" {
+		if m.Pod == nil {
+			return nil
+		}
+		return m.Pod
+	}
+	dexServer := &dexv1.DexServer{}
+	if err := m.Get(ctx, k8stypes.NamespacedName{Name: server}, dexServer); err != nil {
+		m.Log.Error(err, "unable to get dex server", "dexserver", server)
+		return nil
+	}
+	return dexServer
+}
+
+// event records an event for the object, it is skipped when there is none
+func (m *CertificateMonitor) event(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if object == nil {
+		return
+	}
+	m.Recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
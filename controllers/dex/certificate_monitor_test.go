@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// certificateClient is a dex client reporting the given certificate
+type certificateClient struct {
+	dexapi.Interface
+	cert dexapi.Certificate
+}
+
+func (c *certificateClient) Certificate() (dexapi.Certificate, error) {
+	return c.cert, nil
+}
+
+func (c *certificateClient) Close() error {
+	return nil
+}
+
+var _ = Describe("CertificateMonitor", func() {
+	ctx := context.Background()
+	var recorder *record.FakeRecorder
+	var pool *dexapi.Pool
+	var monitor *CertificateMonitor
+
+	newCertificate := func(serial string, validFor time.Duration) dexapi.Certificate {
+		return dexapi.Certificate{
+			Subject:      "CN=dex-operator",
+			SerialNumber: serial,
+			NotAfter:     time.Now().Add(validFor).Truncate(time.Second),
+		}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		pool = dexapi.NewPool(nil)
+		server := &dexv1.DexServer{ObjectMeta: metav1.ObjectMeta{Name: "partners"}}
+		monitor = &CertificateMonitor{
+			Client:        fake.NewFakeClientWithScheme(scheme.Scheme, server),
+			Log:           logf.Log,
+			Recorder:      recorder,
+			DexClients:    pool,
+			WarningPeriod: 7 * 24 * time.Hour,
+		}
+	})
+
+	AfterEach(func() {
+		certificateExpiry.Reset()
+	})
+
+	It("should report loaded and renewed certificates", func() {
+		partners := &certificateClient{cert: newCertificate("1", 30*24*time.Hour)}
+		pool.Set("partners", partners, "hash")
+		monitor.check(ctx)
+		Expect(testutil.ToFloat64(certificateExpiry.WithLabelValues("partners"))).To(Equal(float64(partners.cert.NotAfter.Unix())))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal CertificateLoaded dex client certificate CN=dex-operator")))
+		monitor.check(ctx)
+		Expect(recorder.Events).NotTo(Receive())
+
+		partners.cert = newCertificate("2", 3*24*time.Hour)
+		monitor.check(ctx)
+		Expect(testutil.ToFloat64(certificateExpiry.WithLabelValues("partners"))).To(Equal(float64(partners.cert.NotAfter.Unix())))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal CertificateRenewed")))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning CertificateExpiring")))
+
+		pool.Remove("partners")
+		monitor.check(ctx)
+		Expect(certificateExpiry.DeleteLabelValues("partners")).To(BeFalse())
+	})
+
+	It("should record events about the default instance for the pod", func() {
+		def := &certificateClient{cert: newCertificate("1", -time.Hour)}
+		pool.SetDefault(def)
+		monitor.check(ctx)
+		Expect(testutil.ToFloat64(certificateExpiry.WithLabelValues(""))).To(Equal(float64(def.cert.NotAfter.Unix())))
+		Expect(recorder.Events).NotTo(Receive())
+
+		monitor.Pod = &corev1.ObjectReference{Kind: "Pod", Name: "dex-operator", Namespace: "dex"}
+		def.cert = newCertificate("2", -time.Hour)
+		monitor.check(ctx)
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal CertificateRenewed")))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning CertificateExpired")))
+	})
+})
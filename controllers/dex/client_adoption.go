@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// adoptionEnabled returns true when existing dex clients may be adopted
+func adoptionEnabled(dexv1Client dexv1.ClientObject) bool {
+	policy := dexv1Client.GetClientSpec().AdoptionPolicy
+	return policy == dexv1.AdoptionPolicyIfMatching || policy == dexv1.AdoptionPolicyAlways
+}
+
+// alwaysAdoptionAllowed returns true when the client may take over existing dex clients
+// with AdoptionPolicyAlways, which replaces their secret. ClusterClients are created by
+// cluster admins and always may, Clients only in the namespaces the operator allows.
+func (r *ClientReconciler) alwaysAdoptionAllowed(dexv1Client dexv1.ClientObject) bool {
+	namespace := dexv1Client.GetNamespace()
+	return namespace == "" || containsString(r.AlwaysAdoptionNamespaces, namespace)
+}
+
+// adoptClient takes ownership of an existing dex client with the same ID and updates
+// it to match the spec. With IfMatching, and for retained clients, the secret and
+// public flag of the existing client must match, with Always the client is
+// recreated when they do not if the namespace allows it.
+func (r *ClientReconciler) adoptClient(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest) error {
+	always := dexv1Client.GetClientSpec().AdoptionPolicy == dexv1.AdoptionPolicyAlways && r.alwaysAdoptionAllowed(dexv1Client)
+	return syncExistingClient(ctx, dex, wanted, always)
+}
+
+// syncExistingClient updates an existing dex client to match the wanted one, a client
+// differing in its secret or public flag is recreated when recreate is set.
+func syncExistingClient(ctx context.Context, dex dexapi.Interface, wanted dexapi.CreateClientRequest, recreate bool) error {
+	live, err := dex.GetClient(ctx, wanted.ID)
+	if err != nil {
+		if recreate {
+			return dex.RecreateClient(ctx, wanted)
+		}
+		return fmt.Errorf("unable to compare the existing client: %w", err)
+	}
+	drifted := clientDrift(wanted, live)
+	switch {
+	case len(drifted) == 0:
+		return nil
+	case !immutableDrift(drifted):
+		return dex.UpdateClient(ctx, wanted.UpdateRequest())
+	case recreate:
+		return dex.RecreateClient(ctx, wanted)
+	default:
+		return fmt.Errorf("client %q %w and differs in %s", wanted.ID, dexapi.ErrAlreadyExists, strings.Join(drifted, ", "))
+	}
+}
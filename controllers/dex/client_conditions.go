@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+// setClientCondition sets a condition observed at the current generation of the client
+func setClientCondition(dexv1Client dexv1.ClientObject, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	dexv1.SetCondition(&dexv1Client.GetClientStatus().Conditions, dexv1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: dexv1Client.GetGeneration(),
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// summarizeConditions derives the Ready and Degraded conditions from the state of
+// the client and the DexAvailable, SecretResolved, Synced and Drifted conditions. Drift
+// detection unsupported by dex does not degrade a client.
+func summarizeConditions(dexv1Client dexv1.ClientObject) {
+	status := dexv1Client.GetClientStatus()
+	conditions := status.Conditions
+	active := status.State == dexv1.PhaseActive || status.State == dexv1.PhaseActiveDegraded
+
+	// The first failing condition explains why the client is not as expected
+	var failing *dexv1.Condition
+	if c := dexv1.FindCondition(conditions, dexv1.ConditionDexAvailable); c != nil && c.Status == metav1.ConditionFalse {
+		failing = c
+	} else if c := dexv1.FindCondition(conditions, dexv1.ConditionSecretResolved); c != nil && c.Status == metav1.ConditionFalse {
+		failing = c
+	} else if c := dexv1.FindCondition(conditions, dexv1.ConditionSynced); c != nil && c.Status == metav1.ConditionFalse {
+		failing = c
+	} else if c := dexv1.FindCondition(conditions, dexv1.ConditionDrifted); c != nil && c.Status == metav1.ConditionUnknown &&
+		c.Reason != dexv1.ReasonDriftDetectionUnsupported {
+		failing = c
+	}
+
+	switch {
+	case active && failing != nil:
+		setClientCondition(dexv1Client, dexv1.ConditionDegraded, metav1.ConditionTrue, failing.Reason, failing.Message)
+	default:
+		setClientCondition(dexv1Client, dexv1.ConditionDegraded, metav1.ConditionFalse, dexv1.ReasonAsExpected, "")
+	}
+
+	switch {
+	case active:
+		setClientCondition(dexv1Client, dexv1.ConditionReady, metav1.ConditionTrue, dexv1.ReasonReady, "")
+	case status.State == dexv1.PhaseDeleting:
+		setClientCondition(dexv1Client, dexv1.ConditionReady, metav1.ConditionFalse, dexv1.ReasonDeleting, "")
+	case failing != nil:
+		setClientCondition(dexv1Client, dexv1.ConditionReady, metav1.ConditionFalse, failing.Reason, failing.Message)
+	default:
+		setClientCondition(dexv1Client, dexv1.ConditionReady, metav1.ConditionFalse, dexv1.ReasonCreating, "")
+	}
+}
+
+// saveClient updates the status of the client after deriving its summary conditions
+func (r *ClientReconciler) saveClient(ctx context.Context, dexv1Client dexv1.ClientObject) error {
+	summarizeConditions(dexv1Client)
+	return r.Status().Update(ctx, dexv1Client)
+}
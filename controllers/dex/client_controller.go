@@ -18,14 +18,23 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
 	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
@@ -38,35 +47,114 @@ var (
 			Help: "Number of clients created",
 		},
 	)
+	clientsAdopted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_adopted_total",
+			Help: "Number of existing dex clients adopted",
+		},
+	)
+	clientRetries = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_retries_total",
+			Help: "Number of attempts to create failed dex clients again",
+		},
+	)
 	clientFailures = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Name: "client_failures_total",
 			Help: "Number of failed clients",
 		},
 	)
+	secretRotations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_secret_rotations_total",
+			Help: "Number of client secret rotations",
+		},
+	)
+	secretRotationFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_secret_rotation_failures_total",
+			Help: "Number of failed client secret rotations",
+		},
+	)
+	clientDeletions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "client_deletions_total",
+			Help: "Number of clients removed by outcome: deleted, not_found, retained, forced or timed_out",
+		},
+		[]string{"outcome"},
+	)
+	clientDeletionFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "client_deletion_failures_total",
+			Help: "Number of failed attempts to delete clients from dex",
+		},
+	)
+	clientDriftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "client_drift_corrections_total",
+			Help: "Number of client fields corrected after drifting in dex",
+		},
+		[]string{"field"},
+	)
 )
 
 func init() {
-	metrics.Registry.MustRegister(clientsCreated, clientFailures)
+	metrics.Registry.MustRegister(clientsCreated, clientsAdopted, clientFailures, clientRetries,
+		secretRotations, secretRotationFailures, clientDeletions, clientDeletionFailures, clientDriftCorrections)
 }
 
 // ClientReconciler reconciles a Client object
 type ClientReconciler struct {
 	client.Client
-	Log       logr.Logger
-	Scheme    *runtime.Scheme
-	DexClient *dexapi.APIClient
-	Recorder  record.EventRecorder
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// DexClients holds the connections to the default dex instance and the DexServers
+	DexClients *dexapi.Pool
+	// Prober reports unavailable dex servers, clients wait for them instead of
+	// failing, nil disables waiting
+	Prober   *DexProber
+	Recorder record.EventRecorder
+	// DriftInterval is the interval at which active clients are compared with dex,
+	// zero disables drift detection
+	DriftInterval time.Duration
+	// RetryBaseDelay is the delay before retrying a client which failed with a
+	// transient error, doubled on every further failure
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries
+	RetryMaxDelay time.Duration
+	// FinalizerTimeout is the time after which the finalizer of a deleted client is
+	// removed although the client could not be deleted from dex, zero waits forever
+	FinalizerTimeout time.Duration
+	// DeletionPolicy is the deletion policy of clients without spec.deletionPolicy
+	DeletionPolicy string
+	// RetainedNamespace is the namespace retained clients are recorded in, usually
+	// the one of the operator, empty disables the record
+	RetainedNamespace string
+	// APIReader reads the record of retained clients without the cache, the client
+	// is used when it is nil
+	APIReader client.Reader
+	// ClientIDStrategy derives the dex client ID of Clients without spec.clientID,
+	// one of ClientIDStrategyName, ClientIDStrategyNamespaceName or ClientIDStrategyExplicit
+	ClientIDStrategy string
+	// SecretHashKey keys the hashes of client secrets in the status, see LoadSecretHashKey
+	SecretHashKey []byte
+	// AlwaysAdoptionNamespaces are the namespaces whose Clients may adopt existing dex
+	// clients with AdoptionPolicyAlways, empty allows it for ClusterClients only
+	AlwaysAdoptionNamespaces []string
 }
 
 // +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clients,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clients/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clusterclients,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=dexservers,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
 
 // Reconcile reconciles oidc clients in dex
 func (r *ClientReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
-	log := r.Log.WithValues("client", req.NamespacedName)
 
 	// Get an oauth2 Client
 	dexv1Client := &dexv1.Client{}
@@ -76,117 +164,522 @@ func (r *ClientReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		// on deleted requests.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	return r.reconcileClient(ctx, r.Log.WithValues("client", req.NamespacedName), dexv1Client)
+}
+
+// reconcileClient reconciles a Client or ClusterClient with dex
+func (r *ClientReconciler) reconcileClient(ctx context.Context, log logr.Logger, dexv1Client dexv1.ClientObject) (ctrl.Result, error) {
+	spec, status := dexv1Client.GetClientSpec(), dexv1Client.GetClientStatus()
+
 	// deletion finalizer
 	dexFinalizer := "client.dex.finalizers.betssongroup.com"
 
 	// Delete
 	// examine DeletionTimestamp to determine if object is under deletion
-	if dexv1Client.ObjectMeta.DeletionTimestamp.IsZero() {
-		if !containsString(dexv1Client.ObjectMeta.Finalizers, dexFinalizer) {
+	if dexv1Client.GetDeletionTimestamp().IsZero() {
+		if !containsString(dexv1Client.GetFinalizers(), dexFinalizer) {
 			// append our finalizer
 			log.Info("Adding finalizer")
-			dexv1Client.ObjectMeta.Finalizers = append(dexv1Client.ObjectMeta.Finalizers, dexFinalizer)
-			if err := r.Update(ctx, dexv1Client); err != nil {
+			if err := r.patchClient(ctx, dexv1Client, func() {
+				dexv1Client.SetFinalizers(append(dexv1Client.GetFinalizers(), dexFinalizer))
+			}); err != nil {
 				return ctrl.Result{}, err
 			}
+			// The patch triggers another reconcile, continuing here would let that one
+			// run against a cache without the status written below and call dex twice
+			return ctrl.Result{}, nil
 		}
 	} else {
-		// The object is being deleted
-		if containsString(dexv1Client.ObjectMeta.Finalizers, dexFinalizer) {
-			r.Recorder.Eventf(dexv1Client, "Normal", "ClientDeletion", "client %s", dexv1Client.Name)
-			// our finalizer is present, so lets handle any external dependency
-			if dexv1Client.Status.State != dexv1.PhaseFailed { // It's a failed client, just delete it.
-				dexv1Client.Status.State = dexv1.PhaseDeleting
-				if err := r.DexClient.DeleteClient(ctx, dexv1Client.Name); err != nil {
-					dexv1Client.Status.Message = err.Error()
-					if err := r.Update(ctx, dexv1Client); err != nil {
-						r.Recorder.Eventf(dexv1Client, "Error", "ClientDeletion", "client %s: %s", dexv1Client.Name, err.Error())
-						return ctrl.Result{}, err
-					}
-					return ctrl.Result{}, err
-				}
-			}
-			// remove our finalizer from the list and update it.
-			dexv1Client.ObjectMeta.Finalizers = removeString(dexv1Client.ObjectMeta.Finalizers, dexFinalizer)
-			if err := r.Update(ctx, dexv1Client); err != nil {
-				return ctrl.Result{}, err
-			}
+		// The object is being deleted, our finalizer is present, so lets handle any
+		// external dependency
+		if containsString(dexv1Client.GetFinalizers(), dexFinalizer) {
+			return r.finalizeClient(ctx, log, dexv1Client, dexFinalizer)
 		}
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
 	}
 
+	// Only generated secrets can be rotated on request
+	if _, ok := dexv1Client.GetAnnotations()[dexv1.RotateSecretAnnotation]; ok && !rotatable(dexv1Client) {
+		r.Recorder.Eventf(dexv1Client, "Warning", "SecretRotation", "client %s: only generated secrets can be rotated", dexv1Client.GetName())
+		if err := r.patchClient(ctx, dexv1Client, func() {
+			delete(dexv1Client.GetAnnotations(), dexv1.RotateSecretAnnotation)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Resolve the ID of the client in dex
+	id, err := r.resolveClientID(dexv1Client)
+	if err != nil {
+		log.Error(err, "unable to resolve client ID")
+		status.Message = err.Error()
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonClientIDInvalid, err.Error())
+		r.Recorder.Eventf(dexv1Client, "Warning", "ClientID", "client %s: %s", dexv1Client.GetName(), err.Error())
+		if err := r.saveClient(ctx, dexv1Client); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Only a spec change can fix the ID
+		return ctrl.Result{}, nil
+	}
+
+	// Resolve the dex server the client lives in
+	dex, err := r.dexServer(dexv1Client)
+	// The connection to the default server is built in the background
+	if r.notConnected(dexv1Client, err) {
+		return r.waitForDex(ctx, log, dexv1Client, err)
+	}
+	if err != nil {
+		log.Error(err, "unable to resolve dex server")
+		reason := dexv1.ReasonDexServerUnavailable
+		if errors.Is(err, errDexServerChanged) {
+			reason = dexv1.ReasonDexServerChanged
+		}
+		status.Message = err.Error()
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, reason, err.Error())
+		r.Recorder.Eventf(dexv1Client, "Warning", "DexServer", "client %s: %s", dexv1Client.GetName(), err.Error())
+		if err := r.saveClient(ctx, dexv1Client); err != nil {
+			return ctrl.Result{}, err
+		}
+		// The connection to a new DexServer may not be built yet, only a spec change
+		// fixes a changed server
+		if errors.Is(err, dexapi.ErrUnknownServer) {
+			return ctrl.Result{RequeueAfter: r.RetryBaseDelay}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+	// Wait for dex instead of failing the client while it is unavailable
+	if err := r.dexAvailable(dexv1Client); err != nil {
+		return r.waitForDex(ctx, log, dexv1Client, err)
+	}
+
+	// Resolve the client secret, never log or record its value
+	secret, err := r.clientSecret(ctx, dexv1Client, id)
+	if err != nil {
+		log.Error(err, "unable to resolve client secret")
+		notFound := apierrors.IsNotFound(errors.Unwrap(err))
+		reason := dexv1.ReasonSecretInvalid
+		if notFound {
+			reason = dexv1.ReasonSecretNotFound
+		}
+		status.Message = err.Error()
+		setClientCondition(dexv1Client, dexv1.ConditionSecretResolved, metav1.ConditionFalse, reason, err.Error())
+		r.Recorder.Eventf(dexv1Client, "Warning", "SecretResolution", "client %s: %s", dexv1Client.GetName(), err.Error())
+		if err := r.saveClient(ctx, dexv1Client); err != nil {
+			return ctrl.Result{}, err
+		}
+		// A missing secret is picked up by the secret watch once it is created
+		if notFound {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	// The deprecated secret is reported once per generation, not on every drift check
+	resolved := dexv1.FindCondition(status.Conditions, dexv1.ConditionSecretResolved)
+	reported := resolved != nil && resolved.Status == metav1.ConditionTrue && resolved.ObservedGeneration == dexv1Client.GetGeneration()
+	setClientCondition(dexv1Client, dexv1.ConditionSecretResolved, metav1.ConditionTrue, dexv1.ReasonSecretResolved, "")
+	if !reported && spec.SecretRef == nil && spec.Secret != "" {
+		r.Recorder.Eventf(dexv1Client, "Warning", "DeprecatedSecret", "client %s: spec.secret is deprecated, use spec.secretRef", dexv1Client.GetName())
+	}
+
+	wanted := desiredClient(dexv1Client, id, secret)
+
+	// Rotate generated secrets when due, degraded clients retry their update first
+	if status.State == dexv1.PhaseActive && rotationDue(dexv1Client, time.Now()) {
+		return r.rotateSecret(ctx, dex, dexv1Client, wanted)
+	}
+
 	// if status is not set, set it to CREATING
-	if dexv1Client.Status.State == "" || dexv1Client.Status.State == dexv1.PhaseCreating {
-		dexv1Client.Status.State = dexv1.PhaseCreating
+	if status.State == "" || status.State == dexv1.PhaseCreating {
+		status.State = dexv1.PhaseCreating
+	}
+	// Failed clients are created again once their backoff expired or the spec changed
+	if status.State == dexv1.PhaseFailed && retryDue(dexv1Client, time.Now()) {
+		if dexv1Client.GetGeneration() != status.ObservedGeneration {
+			status.FailedAttempts = 0
+		}
+		log.Info("Retrying failed client", "attempt", status.FailedAttempts+1)
+		clientRetries.Inc()
+		status.State = dexv1.PhaseCreating
 	}
 	// Now let's make the main case distinction: implementing
 	// the state diagram CREATING -> ACTIVE or CREATING -> FAILED
-	switch dexv1Client.Status.State {
+	switch status.State {
 	case dexv1.PhaseCreating:
-		log.Info("Creating dex client", "name", dexv1Client.Name)
-		// Implement dex auth client creation here
-		res, err := r.DexClient.CreateClient(
-			ctx,
-			dexv1Client.Spec.RedirectURIs,
-			dexv1Client.Spec.TrustedPeers,
-			dexv1Client.Spec.Public,
-			dexv1Client.Spec.Name,
-			dexv1Client.Name,
-			dexv1Client.Spec.LogoURL,
-			dexv1Client.Spec.Secret,
-		)
+		log.Info("Creating dex client", "name", dexv1Client.GetName(), "client ID", id)
+		adopted, err := r.createClient(ctx, dex, dexv1Client, wanted)
 		if err != nil {
-			log.Error(err, "Client create failed", "client", dexv1Client.Name)
-			dexv1Client.Status.State = dexv1.PhaseFailed
-			dexv1Client.Status.Message = err.Error()
-			r.Recorder.Eventf(dexv1Client, "Error", "ClientCreation", "client %s: %s", dexv1Client.Name, err.Error())
+			log.Error(err, "Client create failed", "client", dexv1Client.GetName())
+			r.createFailed(dexv1Client, err, time.Now())
+			setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonCreateFailed, err.Error())
+			r.Recorder.Eventf(dexv1Client, "Error", "ClientCreation", "client %s: %s", dexv1Client.GetName(), err.Error())
 			clientFailures.Inc()
 		} else {
-			dexv1Client.Status.State = dexv1.PhaseActive
-			log.Info("Client created", "client ID", res.GetId())
-			r.Recorder.Eventf(dexv1Client, "Normal", "ClientCreation", "client %s", dexv1Client.Name)
-			clientsCreated.Inc()
+			status.State = dexv1.PhaseActive
+			status.Message = ""
+			status.ClientID = id
+			status.SecretHash = hashSecret(r.SecretHashKey, secret)
+			status.Public = &spec.Public
+			status.ObservedGeneration = dexv1Client.GetGeneration()
+			status.FailedAttempts = 0
+			status.NextRetryTime = nil
+			if adopted {
+				setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonAdopted, "")
+				log.Info("Client adopted", "client ID", id)
+				r.Recorder.Eventf(dexv1Client, "Normal", "ClientAdoption", "client %s", dexv1Client.GetName())
+				clientsAdopted.Inc()
+			} else {
+				setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonCreated, "")
+				log.Info("Client created", "client ID", id)
+				r.Recorder.Eventf(dexv1Client, "Normal", "ClientCreation", "client %s", dexv1Client.GetName())
+				clientsCreated.Inc()
+			}
+		}
+	case dexv1.PhaseActive, dexv1.PhaseActiveDegraded:
+		// A degraded client failed to update and is handled like an active one until
+		// the update went through.
+		// Dex can not update the secret or public flag of a client, they are changed
+		// by recreating it unless the update strategy rejects such changes
+		if status.ClientID == "" {
+			status.ClientID = id
+		}
+		if status.SecretHash == "" {
+			status.SecretHash = hashSecret(r.SecretHashKey, secret)
+		}
+		if status.Public == nil {
+			status.Public = &spec.Public
+		}
+		if changed := immutableChanges(dexv1Client, r.SecretHashKey, secret); len(changed) > 0 {
+			if rejected := rejectableChanges(dexv1Client, changed); len(rejected) > 0 && spec.UpdateStrategy == dexv1.UpdateStrategyReject {
+				return r.rejectChanges(ctx, dexv1Client, rejected)
+			}
+			return r.recreateClient(ctx, dex, dexv1Client, wanted, changed)
+		}
+		// An unchanged spec is only compared with the live client in dex
+		if dexv1Client.GetGeneration() == status.ObservedGeneration {
+			if r.DriftInterval == 0 {
+				break
+			}
+			if err := r.reconcileDrift(ctx, dex, dexv1Client, wanted); err != nil {
+				log.Error(err, "Client drift detection failed", "client", dexv1Client.GetName())
+				status.Message = err.Error()
+				if err := r.saveClient(ctx, dexv1Client); err != nil {
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{}, err
+			}
+			status.State = dexv1.PhaseActive
+			setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonInSync, "")
+			break
 		}
-	case dexv1.PhaseActive:
 		// If the client is active but in the reconcile loop it's being updated.
-		log.Info("Client update", "client ID", dexv1Client.Name)
-		err := r.DexClient.UpdateClient(
-			ctx,
-			dexv1Client.Name,
-			dexv1Client.Spec.RedirectURIs,
-			dexv1Client.Spec.TrustedPeers,
-			dexv1Client.Spec.Public,
-			dexv1Client.Spec.Name,
-			dexv1Client.Spec.LogoURL,
-		)
+		log.Info("Client update", "client ID", id)
+		err := dex.UpdateClient(ctx, wanted.UpdateRequest())
 		if err != nil {
-			log.Error(err, "Client update failed", "client", dexv1Client.Name)
-			dexv1Client.Status.State = dexv1.PhaseActiveDegraded
-			dexv1Client.Status.Message = err.Error()
+			log.Error(err, "Client update failed", "client", dexv1Client.GetName())
+			status.State = dexv1.PhaseActiveDegraded
+			status.Message = err.Error()
+			setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonUpdateFailed, err.Error())
 		} else {
-			log.Info("Client updated", "client ID", dexv1Client.Name)
-			r.Recorder.Eventf(dexv1Client, "Normal", "ClientUpdate", "client %s", dexv1Client.Name)
+			status.State = dexv1.PhaseActive
+			status.Message = ""
+			status.ObservedGeneration = dexv1Client.GetGeneration()
+			setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonUpdated, "")
+			log.Info("Client updated", "client ID", id)
+			r.Recorder.Eventf(dexv1Client, "Normal", "ClientUpdate", "client %s", dexv1Client.GetName())
 		}
 	case dexv1.PhaseFailed:
-		log.Info("Client failed")
+		if status.NextRetryTime == nil {
+			log.Info("Client failed permanently, waiting for a spec change")
+		} else {
+			log.Info("Client failed", "next retry", status.NextRetryTime.Time)
+		}
 	default:
 		// Should never reach here
-		log.Info("Got an invalid state", "state", dexv1Client.Status.State)
+		log.Info("Got an invalid state", "state", status.State)
 		return ctrl.Result{}, nil
 	}
 	// Update the object and return
-	err := r.Update(ctx, dexv1Client)
+	err = r.saveClient(ctx, dexv1Client)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	return ctrl.Result{RequeueAfter: r.requeueAfter(dexv1Client)}, nil
+}
+
+// requeueAfter returns when an active client needs to be reconciled again for
+// secret rotation or drift detection, or when a failed client or update is retried
+func (r *ClientReconciler) requeueAfter(dexv1Client dexv1.ClientObject) time.Duration {
+	status := dexv1Client.GetClientStatus()
+	if status.State == dexv1.PhaseActiveDegraded {
+		return r.retryBackoff(1)
+	}
+	if status.State == dexv1.PhaseFailed && status.NextRetryTime != nil {
+		if after := time.Until(status.NextRetryTime.Time); after > 0 {
+			return after
+		}
+		return time.Second
+	}
+	if status.State != dexv1.PhaseActive {
+		return 0
+	}
+	after := r.DriftInterval
+	if next := nextRotation(dexv1Client, time.Now()); next > 0 && (after == 0 || next < after) {
+		after = next
+	}
+	return after
+}
+
+// createFailed moves the client to the failed phase, transient errors are retried
+// with exponential backoff while permanent errors wait for a spec change.
+func (r *ClientReconciler) createFailed(dexv1Client dexv1.ClientObject, err error, now time.Time) {
+	status := dexv1Client.GetClientStatus()
+	status.State = dexv1.PhaseFailed
+	status.Message = err.Error()
+	status.ObservedGeneration = dexv1Client.GetGeneration()
+	status.FailedAttempts++
+	if dexapi.IsPermanent(err) {
+		status.NextRetryTime = nil
+		return
+	}
+	next := metav1.NewTime(now.Add(r.retryBackoff(status.FailedAttempts)))
+	status.NextRetryTime = &next
+}
+
+// retryBackoff returns the delay before the next attempt after the given number of failures
+func (r *ClientReconciler) retryBackoff(attempts int32) time.Duration {
+	delay := r.RetryBaseDelay
+	for i := int32(1); i < attempts && delay < r.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if r.RetryMaxDelay > 0 && delay > r.RetryMaxDelay {
+		delay = r.RetryMaxDelay
+	}
+	return delay
+}
+
+// retryDue returns true when a failed client should be created again
+func retryDue(dexv1Client dexv1.ClientObject, now time.Time) bool {
+	status := dexv1Client.GetClientStatus()
+	if dexv1Client.GetGeneration() != status.ObservedGeneration {
+		return true
+	}
+	next := status.NextRetryTime
+	return next != nil && !now.Before(next.Time)
+}
+
+// clientSecret returns the secret to push to dex, generating it when requested
+func (r *ClientReconciler) clientSecret(ctx context.Context, dexv1Client dexv1.ClientObject, id string) (string, error) {
+	spec := dexv1Client.GetClientSpec()
+	if spec.SecretRef == nil && spec.SecretGeneration != nil {
+		return r.reconcileGeneratedSecret(ctx, dexv1Client, id)
+	}
+	return resolveClientSecret(ctx, r, dexv1Client)
+}
+
+// patchClient applies the changes made by mutate to the metadata of the client with a
+// merge patch, the status computed so far is kept.
+func (r *ClientReconciler) patchClient(ctx context.Context, dexv1Client dexv1.ClientObject, mutate func()) error {
+	status := dexv1Client.GetClientStatus().DeepCopy()
+	patch := client.MergeFrom(dexv1Client.DeepCopyObject())
+	mutate()
+	if err := r.Patch(ctx, dexv1Client, patch); err != nil {
+		return err
+	}
+	*dexv1Client.GetClientStatus() = *status
+	return nil
+}
+
+// desiredClient returns the dex client described by the spec
+func desiredClient(dexv1Client dexv1.ClientObject, id string, secret string) dexapi.CreateClientRequest {
+	spec := dexv1Client.GetClientSpec()
+	return dexapi.CreateClientRequest{
+		ID:           id,
+		Secret:       secret,
+		RedirectURIs: spec.RedirectURIs,
+		TrustedPeers: spec.TrustedPeers,
+		Public:       spec.Public,
+		Name:         spec.Name,
+		LogoURL:      spec.LogoURL,
+	}
+}
+
+// createClient creates the dex client, adopting an existing client with the same ID
+// when the adoption policy allows it or the client was retained by a deleted Client.
+// A client ID owned by another Client or ClusterClient is never created or adopted.
+func (r *ClientReconciler) createClient(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest) (bool, error) {
+	owner, err := r.clientIDOwner(ctx, dexv1Client, wanted.ID)
+	if err != nil {
+		return false, err
+	}
+	// The cached owners miss clients created at the same time, the claim settles them
+	claimed := false
+	if owner == "" {
+		if owner, claimed, err = r.claimClientID(ctx, dexv1Client, wanted.ID); err != nil {
+			return false, err
+		}
+	}
+	if owner != "" {
+		return false, fmt.Errorf("client %q %w, it is owned by %s", wanted.ID, dexapi.ErrAlreadyExists, owner)
+	}
+	_, err = dex.CreateClient(ctx, wanted)
+	if !errors.Is(err, dexapi.ErrAlreadyExists) {
+		return false, err
+	}
+	// An earlier attempt created the client when the claim or the client ID in the
+	// status predate this one, e.g. when writing the status afterwards failed
+	if claimed || dexv1Client.GetClientStatus().ClientID == wanted.ID {
+		r.Log.Info("Client was created by an earlier attempt", "client", dexv1Client.GetName(), "client ID", wanted.ID)
+		return false, syncExistingClient(ctx, dex, wanted, true)
+	}
+	adopted, err := r.adoptExistingClient(ctx, dex, dexv1Client, wanted, err)
+	if err != nil {
+		// The client in dex belongs to someone else, a retry must not take it for the
+		// one created by this client
+		if releaseErr := r.releaseClientID(ctx, dexv1Client, wanted.ID); releaseErr != nil {
+			r.Log.Error(releaseErr, "unable to release the client ID", "client ID", wanted.ID)
+		}
+	}
+	return adopted, err
+}
+
+// adoptExistingClient adopts the dex client which existed before the client was
+// created, err is the conflict returned when creating the client
+func (r *ClientReconciler) adoptExistingClient(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest, err error) (bool, error) {
+	server := dexv1Client.GetClientStatus().DexServer
+	retained, retainedErr := r.retainedBy(ctx, server, wanted.ID)
+	if retainedErr != nil {
+		return false, retainedErr
+	}
+	if retained == nil && !adoptionEnabled(dexv1Client) {
+		return false, err
+	}
+	// Only retained clients may be taken over where Always is not allowed
+	if retained == nil && dexv1Client.GetClientSpec().AdoptionPolicy == dexv1.AdoptionPolicyAlways && !r.alwaysAdoptionAllowed(dexv1Client) {
+		return false, fmt.Errorf("%w, adoptionPolicy %s is not allowed in namespace %s", err, dexv1.AdoptionPolicyAlways, dexv1Client.GetNamespace())
+	}
+	if err := r.adoptClient(ctx, dex, dexv1Client, wanted); err != nil {
+		return true, err
+	}
+	if retained != nil {
+		r.Log.Info("Adopted retained client", "client", dexv1Client.GetName(), "client ID", wanted.ID,
+			"retainedBy", retained.String())
+		if err := r.forgetRetained(ctx, server, wanted.ID); err != nil {
+			r.Log.Error(err, "unable to forget the retained client", "client ID", wanted.ID)
+		}
+	}
+	return true, nil
+}
+
+// immutableChanges returns the fields dex can not update in place which changed since
+// the client was last pushed to dex
+func immutableChanges(dexv1Client dexv1.ClientObject, key []byte, secret string) []string {
+	spec, status := dexv1Client.GetClientSpec(), dexv1Client.GetClientStatus()
+	var changed []string
+	if status.SecretHash != "" && !secretMatches(key, status.SecretHash, secret) {
+		changed = append(changed, fieldSecret)
+	}
+	if status.Public != nil && *status.Public != spec.Public {
+		changed = append(changed, fieldPublic)
+	}
+	return changed
+}
+
+// rejectableChanges returns the changed fields the update strategy may refuse, the
+// operator owns generated secrets so regenerating them always recreates the client.
+func rejectableChanges(dexv1Client dexv1.ClientObject, changed []string) []string {
+	if !rotatable(dexv1Client) {
+		return changed
+	}
+	var rejectable []string
+	for _, field := range changed {
+		if field != fieldSecret {
+			rejectable = append(rejectable, field)
+		}
+	}
+	return rejectable
+}
+
+// rejectChanges refuses changes to immutable fields, the client is left untouched in
+// dex until they are reverted or the update strategy allows recreating it.
+func (r *ClientReconciler) rejectChanges(ctx context.Context, dexv1Client dexv1.ClientObject, changed []string) (ctrl.Result, error) {
+	status := dexv1Client.GetClientStatus()
+	message := fmt.Sprintf("%s can not be changed in place and updateStrategy is %s, revert the change or use %s",
+		strings.Join(changed, ", "), dexv1.UpdateStrategyReject, dexv1.UpdateStrategyRecreate)
+	r.Log.Info("Rejecting change to immutable fields", "client", dexv1Client.GetName(), "fields", changed)
+	status.Message = message
+	setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonImmutableFieldChanged, message)
+	r.Recorder.Eventf(dexv1Client, "Warning", "ImmutableFieldChange", "client %s: %s", dexv1Client.GetName(), message)
+	if err := r.saveClient(ctx, dexv1Client); err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
+// recreateClient deletes and creates the dex client to apply changes to immutable fields
+func (r *ClientReconciler) recreateClient(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest, changed []string) (ctrl.Result, error) {
+	spec, status := dexv1Client.GetClientSpec(), dexv1Client.GetClientStatus()
+	log := r.Log.WithValues("client", dexv1Client.GetName())
+	log.Info("Immutable fields changed, recreating client", "fields", changed)
+	err := dex.RecreateClient(ctx, wanted)
+	if err != nil {
+		if errors.Is(err, dexapi.ErrNotDeleted) {
+			// The old client is still in dex, the recreate is retried from the current phase
+			log.Error(err, "Client delete failed", "client", dexv1Client.GetName())
+		} else {
+			// The client is gone from dex, let the creating phase bring it back
+			log.Error(err, "Client create failed", "client", dexv1Client.GetName())
+			status.State = dexv1.PhaseCreating
+		}
+		status.Message = err.Error()
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonRecreateFailed, err.Error())
+		if err := r.saveClient(ctx, dexv1Client); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+	status.State = dexv1.PhaseActive
+	status.SecretHash = hashSecret(r.SecretHashKey, wanted.Secret)
+	status.Public = &spec.Public
+	status.ObservedGeneration = dexv1Client.GetGeneration()
+	status.Message = ""
+	setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonRecreated, "")
+	r.Recorder.Eventf(dexv1Client, "Normal", "ClientRecreate", "client %s: %s changed", dexv1Client.GetName(), strings.Join(changed, ", "))
+	if err := r.saveClient(ctx, dexv1Client); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.requeueAfter(dexv1Client)}, nil
+}
+
 // SetupWithManager sets up the mananager
 func (r *ClientReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.Client{}, secretRefIndexKey, indexClientSecretRef); err != nil {
+		return err
+	}
+	// Clients and ClusterClients share the client IDs of dex, both are indexed here so the
+	// ownership checks work without the ClusterClientReconciler
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.Client{}, clientIDIndexKey, indexClientID); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ClusterClient{}, clientIDIndexKey, indexClientID); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.Client{}, dexServerIndexKey, indexDexServerRef); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.Client{}, dexServerStatusIndexKey, indexDexServerStatus); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&dexv1.Client{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.clientsForSecret),
+		}).
+		Watches(&source.Kind{Type: &dexv1.DexServer{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.clientsForDexServer),
+		}).
+		WithEventFilter(ignoreStatusUpdates{}).
 		Complete(r)
 }
 
@@ -17,13 +17,28 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var _ = Context("Inside of a new namespace", func() {
@@ -50,6 +65,774 @@ var _ = Context("Inside of a new namespace", func() {
 			Expect(err).NotTo(HaveOccurred(), "failed to create test Client resource")
 		})
 	})
+
+	Describe("against dex", func() {
+		newClient := func(name string) *dexv1.Client {
+			return &dexv1.Client{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns.Name},
+				Spec: dexv1.ClientSpec{
+					Secret:       "xxx-xxx-xxx-xxx",
+					Name:         "Testing Client",
+					RedirectURIs: redirectURLs,
+				},
+			}
+		}
+		clientState := func(dexv1Client *dexv1.Client) func() string {
+			return func() string {
+				latest := &dexv1.Client{}
+				key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: dexv1Client.Namespace}
+				if err := k8sClient.Get(ctx, key, latest); err != nil {
+					return err.Error()
+				}
+				return latest.Status.State
+			}
+		}
+		clientID := func(dexv1Client *dexv1.Client) string {
+			return ns.Name + "-" + dexv1Client.Name
+		}
+
+		It("should create, update and delete the client in dex", func() {
+			dexv1Client := newClient("lifecycle")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+			live := fakeDex.Client(clientID(dexv1Client))
+			Expect(live).NotTo(BeNil())
+			Expect(live.Name).To(Equal("Testing Client"))
+			Expect(live.Secret).To(Equal("xxx-xxx-xxx-xxx"))
+
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+					return err
+				}
+				dexv1Client.Spec.RedirectURIs = []string{"https://www.betssongroup.com/callback"}
+				return k8sClient.Update(ctx, dexv1Client)
+			}, 10*time.Second).Should(Succeed())
+			Eventually(func() []string {
+				return fakeDex.Client(clientID(dexv1Client)).GetRedirectUris()
+			}, 10*time.Second).Should(ConsistOf("https://www.betssongroup.com/callback"))
+
+			Expect(k8sClient.Delete(ctx, dexv1Client)).To(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.Client{}))
+			}, 10*time.Second).Should(BeTrue())
+			Expect(fakeDex.Client(clientID(dexv1Client))).To(BeNil())
+		})
+
+		It("should keep the client active when recreating fails to delete it", func() {
+			dexv1Client := newClient("recreate")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			fakeDex.Inject(dextest.Fault{Method: "DeleteClient", Code: codes.Unavailable, Times: 1})
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+					return err
+				}
+				dexv1Client.Spec.Secret = "yyy-yyy-yyy-yyy"
+				return k8sClient.Update(ctx, dexv1Client)
+			}, 10*time.Second).Should(Succeed())
+			Eventually(func() string {
+				return fakeDex.Client(clientID(dexv1Client)).GetSecret()
+			}, 10*time.Second).Should(Equal("yyy-yyy-yyy-yyy"))
+			Expect(fakeDex.Calls("DeleteClient")).To(BeNumerically(">=", 2))
+			Expect(clientState(dexv1Client)()).To(Equal(dexv1.PhaseActive))
+		})
+
+		It("should retry the update of degraded clients", func() {
+			dexv1Client := newClient("degraded")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			fakeDex.Inject(dextest.Fault{Method: "UpdateClient", Code: codes.Unavailable, Times: 1})
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+					return err
+				}
+				dexv1Client.Spec.RedirectURIs = []string{"https://www.betssongroup.com/callback"}
+				return k8sClient.Update(ctx, dexv1Client)
+			}, 10*time.Second).Should(Succeed())
+			Eventually(func() []string {
+				return fakeDex.Client(clientID(dexv1Client)).GetRedirectUris()
+			}, 10*time.Second).Should(ConsistOf("https://www.betssongroup.com/callback"))
+			Expect(fakeDex.Calls("UpdateClient")).To(Equal(2))
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+		})
+
+		It("should push regenerated secrets despite the reject strategy", func() {
+			dexv1Client := newClient("regenerated")
+			dexv1Client.Spec.Secret = ""
+			dexv1Client.Spec.SecretGeneration = &dexv1.SecretGeneration{}
+			dexv1Client.Spec.UpdateStrategy = dexv1.UpdateStrategyReject
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+			first := fakeDex.Client(clientID(dexv1Client)).GetSecret()
+
+			secret := &corev1.Secret{}
+			secretKey := k8stypes.NamespacedName{Name: "client-secret-" + dexv1Client.Name, Namespace: ns.Name}
+			Expect(k8sClient.Get(ctx, secretKey, secret)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			Eventually(func() string {
+				return fakeDex.Client(clientID(dexv1Client)).GetSecret()
+			}, 10*time.Second).ShouldNot(Equal(first))
+			Eventually(func() (string, error) {
+				if err := k8sClient.Get(ctx, secretKey, secret); err != nil {
+					return "", err
+				}
+				return string(secret.Data[generatedSecretKey]), nil
+			}, 10*time.Second).Should(Equal(fakeDex.Client(clientID(dexv1Client)).GetSecret()))
+		})
+
+		It("should only give a client ID to one of two clients created together", func() {
+			first, second := newClient("claim-a"), newClient("claim-b")
+			first.Spec.ClientID = ns.Name + "-claim"
+			second.Spec.ClientID = ns.Name + "-claim"
+			second.Spec.AdoptionPolicy = dexv1.AdoptionPolicyIfMatching
+			Expect(k8sClient.Create(ctx, first)).To(Succeed())
+			Expect(k8sClient.Create(ctx, second)).To(Succeed())
+			states := func() []string {
+				return []string{clientState(first)(), clientState(second)()}
+			}
+			Eventually(states, 10*time.Second).Should(ConsistOf(dexv1.PhaseActive, dexv1.PhaseFailed))
+			Consistently(states, time.Second).Should(ConsistOf(dexv1.PhaseActive, dexv1.PhaseFailed))
+		})
+
+		It("should retry transient failures", func() {
+			fakeDex.Inject(dextest.Fault{Method: "CreateClient", Code: codes.Unavailable, Times: 2})
+			dexv1Client := newClient("transient")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+			Expect(fakeDex.Calls("CreateClient")).To(BeNumerically(">=", 3))
+			Expect(fakeDex.Client(clientID(dexv1Client))).NotTo(BeNil())
+		})
+
+		It("should fail clients whose ID exists in dex", func() {
+			fakeDex.Inject(dextest.Fault{Method: "CreateClient", AlreadyExists: true})
+			dexv1Client := newClient("conflict")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseFailed))
+			Consistently(func() int { return fakeDex.Calls("CreateClient") }, time.Second).Should(Equal(1))
+			Expect(fakeDex.Clients()).To(BeEmpty())
+		})
+
+		It("should not take over clients where the always adoption policy is not allowed", func() {
+			dexv1Client := newClient("takeover")
+			dexv1Client.Spec.AdoptionPolicy = dexv1.AdoptionPolicyAlways
+			fakeDex.AddClient(&dexapi.Client{Id: clientID(dexv1Client), Secret: "owned-by-someone-else", Name: "Other"})
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseFailed))
+			Expect(fakeDex.Client(clientID(dexv1Client)).GetSecret()).To(Equal("owned-by-someone-else"))
+		})
+
+		It("should retry failed deletions", func() {
+			dexv1Client := newClient("deletion")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			fakeDex.Inject(dextest.Fault{Method: "DeleteClient", Code: codes.Unavailable, Times: 1})
+			Expect(k8sClient.Delete(ctx, dexv1Client)).To(Succeed())
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.Client{}))
+			}, 10*time.Second).Should(BeTrue())
+			Expect(fakeDex.Calls("DeleteClient")).To(Equal(2))
+		})
+
+		It("should treat clients already gone from dex as deleted", func() {
+			dexv1Client := newClient("gone")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			fakeDex.Inject(dextest.Fault{Method: "DeleteClient", NotFound: true})
+			Expect(k8sClient.Delete(ctx, dexv1Client)).To(Succeed())
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.Client{}))
+			}, 10*time.Second).Should(BeTrue())
+			Expect(fakeDex.Calls("DeleteClient")).To(Equal(1))
+		})
+
+		It("should leave force deleted clients in dex", func() {
+			dexv1Client := newClient("forced")
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			fakeDex.Inject(dextest.Fault{Method: "DeleteClient", Code: codes.Unavailable})
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, key, dexv1Client); err != nil {
+					return err
+				}
+				dexv1Client.Annotations = map[string]string{dexv1.ForceDeleteAnnotation: "true"}
+				return k8sClient.Update(ctx, dexv1Client)
+			}, 10*time.Second).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, dexv1Client)).To(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.Client{}))
+			}, 10*time.Second).Should(BeTrue())
+			Expect(fakeDex.Calls("DeleteClient")).To(BeZero())
+			Expect(fakeDex.Client(clientID(dexv1Client))).NotTo(BeNil())
+		})
+
+		It("should retain clients and adopt them with a new Client", func() {
+			dexv1Client := newClient("retained")
+			dexv1Client.Spec.DeletionPolicy = dexv1.DeletionPolicyRetain
+			Expect(k8sClient.Create(ctx, dexv1Client)).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+
+			Expect(k8sClient.Delete(ctx, dexv1Client)).To(Succeed())
+			key := k8stypes.NamespacedName{Name: dexv1Client.Name, Namespace: ns.Name}
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, key, &dexv1.Client{}))
+			}, 10*time.Second).Should(BeTrue())
+			Expect(fakeDex.Calls("DeleteClient")).To(BeZero())
+			Expect(fakeDex.Client(clientID(dexv1Client))).NotTo(BeNil())
+			records := &corev1.ConfigMap{}
+			recordsKey := k8stypes.NamespacedName{Name: RetainedClientsConfigMap, Namespace: ns.Name}
+			Expect(k8sClient.Get(ctx, recordsKey, records)).To(Succeed())
+			Expect(records.Data[retainedClientsKey]).To(ContainSubstring(clientID(dexv1Client)))
+
+			// The new Client adopts the retained client although its adoption policy is Never
+			Expect(k8sClient.Create(ctx, newClient("retained"))).To(Succeed())
+			Eventually(clientState(dexv1Client), 10*time.Second).Should(Equal(dexv1.PhaseActive))
+			Expect(k8sClient.Get(ctx, recordsKey, records)).To(Succeed())
+			Expect(records.Data[retainedClientsKey]).NotTo(ContainSubstring(clientID(dexv1Client)))
+		})
+	})
+})
+
+var _ = Describe("resolveClientSecret", func() {
+	ctx := context.TODO()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc", Namespace: "team-a"},
+		Data:       map[string][]byte{"clientSecret": []byte("s3cr3t-value")},
+	}
+	newClient := func(ref *dexv1.SecretReference) *dexv1.Client {
+		return &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"},
+			Spec:       dexv1.ClientSpec{Secret: "inline-secret", SecretRef: ref},
+		}
+	}
+
+	It("should prefer the referenced secret over the inline one", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+		value, err := resolveClientSecret(ctx, c, newClient(&dexv1.SecretReference{Name: "oidc", Key: "clientSecret"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("s3cr3t-value"))
+	})
+
+	It("should fall back to the inline secret", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme)
+		value, err := resolveClientSecret(ctx, c, newClient(nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("inline-secret"))
+	})
+
+	It("should not leak the secret value when the key is missing", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+		_, err := resolveClientSecret(ctx, c, newClient(&dexv1.SecretReference{Name: "oidc", Key: "missing"}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).NotTo(ContainSubstring("s3cr3t-value"))
+	})
+
+	It("should refuse secrets in another namespace", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+		_, err := resolveClientSecret(ctx, c, newClient(&dexv1.SecretReference{Name: "oidc", Key: "clientSecret", Namespace: "team-b"}))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("generateSecret", func() {
+	It("should default to 40 alphanumeric characters", func() {
+		secret, err := generateSecret(&dexv1.SecretGeneration{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret).To(MatchRegexp("^[a-zA-Z0-9]{40}$"))
+	})
+
+	It("should honour the length and charset", func() {
+		secret, err := generateSecret(&dexv1.SecretGeneration{Length: 64, Charset: dexv1.CharsetHex})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret).To(MatchRegexp("^[0-9a-f]{64}$"))
+	})
+
+	It("should not repeat itself", func() {
+		first, _ := generateSecret(&dexv1.SecretGeneration{})
+		second, _ := generateSecret(&dexv1.SecretGeneration{})
+		Expect(first).NotTo(Equal(second))
+	})
+})
+
+var _ = Describe("secret rotation", func() {
+	now := time.Now()
+	newClient := func(lastRotation time.Time) *dexv1.Client {
+		return &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "grafana",
+				CreationTimestamp: metav1.NewTime(now.Add(-100 * 24 * time.Hour)),
+			},
+			Spec: dexv1.ClientSpec{
+				SecretGeneration: &dexv1.SecretGeneration{},
+				Rotation:         &dexv1.SecretRotation{Interval: metav1.Duration{Duration: 90 * 24 * time.Hour}},
+			},
+			Status: dexv1.ClientStatus{LastRotationTime: &metav1.Time{Time: lastRotation}},
+		}
+	}
+
+	It("should be due once the interval passed", func() {
+		Expect(rotationDue(newClient(now.Add(-91*24*time.Hour)), now)).To(BeTrue())
+		Expect(rotationDue(newClient(now.Add(-89*24*time.Hour)), now)).To(BeFalse())
+		Expect(nextRotation(newClient(now.Add(-89*24*time.Hour)), now)).To(Equal(24 * time.Hour))
+	})
+
+	It("should count from the creation without previous rotations", func() {
+		dexv1Client := newClient(now)
+		dexv1Client.Status.LastRotationTime = nil
+		Expect(rotationDue(dexv1Client, now)).To(BeTrue())
+	})
+
+	It("should be due when requested through the annotation", func() {
+		dexv1Client := newClient(now)
+		dexv1Client.Annotations = map[string]string{dexv1.RotateSecretAnnotation: "now"}
+		Expect(rotationDue(dexv1Client, now)).To(BeTrue())
+	})
+
+	It("should never rotate secrets it does not own", func() {
+		dexv1Client := newClient(now.Add(-91 * 24 * time.Hour))
+		dexv1Client.Spec.SecretRef = &dexv1.SecretReference{Name: "oidc", Key: "clientSecret"}
+		Expect(rotationDue(dexv1Client, now)).To(BeFalse())
+		Expect(nextRotation(dexv1Client, now)).To(BeZero())
+	})
+})
+
+var _ = Describe("clientDrift", func() {
+	wanted := dexapi.CreateClientRequest{
+		ID:           "grafana",
+		Secret:       "s3cr3t",
+		RedirectURIs: []string{"https://a/callback", "https://b/callback"},
+		Name:         "Grafana",
+	}
+
+	It("should ignore the order of redirect URIs", func() {
+		live := &dexapi.Client{
+			Id:           "grafana",
+			Secret:       "s3cr3t",
+			RedirectUris: []string{"https://b/callback", "https://a/callback"},
+			Name:         "Grafana",
+		}
+		Expect(clientDrift(wanted, live)).To(BeEmpty())
+	})
+
+	It("should report every divergent field", func() {
+		live := &dexapi.Client{
+			Id:           "grafana",
+			Secret:       "other",
+			RedirectUris: []string{"https://a/callback"},
+			Public:       true,
+			Name:         "Grafana",
+		}
+		Expect(clientDrift(wanted, live)).To(ConsistOf(fieldSecret, fieldPublic, fieldRedirectURIs))
+	})
+
+	It("should not compare the secret when none is wanted", func() {
+		public := wanted
+		public.Secret = ""
+		public.Public = true
+		live := &dexapi.Client{
+			Id:           "grafana",
+			Secret:       "generated-by-dex",
+			RedirectUris: []string{"https://a/callback", "https://b/callback"},
+			Public:       true,
+			Name:         "Grafana",
+		}
+		Expect(clientDrift(public, live)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("adoptionEnabled", func() {
+	It("should only adopt clients that allow it", func() {
+		dexv1Client := &dexv1.Client{}
+		Expect(adoptionEnabled(dexv1Client)).To(BeFalse())
+		dexv1Client.Spec.AdoptionPolicy = dexv1.AdoptionPolicyNever
+		Expect(adoptionEnabled(dexv1Client)).To(BeFalse())
+		dexv1Client.Spec.AdoptionPolicy = dexv1.AdoptionPolicyIfMatching
+		Expect(adoptionEnabled(dexv1Client)).To(BeTrue())
+		dexv1Client.Spec.AdoptionPolicy = dexv1.AdoptionPolicyAlways
+		Expect(adoptionEnabled(dexv1Client)).To(BeTrue())
+	})
+})
+
+var _ = Describe("alwaysAdoptionAllowed", func() {
+	It("should only allow the always adoption policy in listed namespaces", func() {
+		r := &ClientReconciler{AlwaysAdoptionNamespaces: []string{"platform"}}
+		Expect(r.alwaysAdoptionAllowed(&dexv1.Client{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}})).To(BeFalse())
+		Expect(r.alwaysAdoptionAllowed(&dexv1.Client{ObjectMeta: metav1.ObjectMeta{Namespace: "platform"}})).To(BeTrue())
+		Expect(r.alwaysAdoptionAllowed(&dexv1.ClusterClient{})).To(BeTrue())
+	})
+})
+
+var _ = Describe("failed client retries", func() {
+	r := &ClientReconciler{RetryBaseDelay: time.Second, RetryMaxDelay: 10 * time.Second}
+	now := time.Now()
+
+	It("should back off exponentially up to the maximum delay", func() {
+		Expect(r.retryBackoff(1)).To(Equal(time.Second))
+		Expect(r.retryBackoff(3)).To(Equal(4 * time.Second))
+		Expect(r.retryBackoff(5)).To(Equal(10 * time.Second))
+		Expect(r.retryBackoff(100)).To(Equal(10 * time.Second))
+	})
+
+	It("should schedule a retry for transient errors", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Generation = 2
+		r.createFailed(dexv1Client, status.Error(codes.Unavailable, "connection refused"), now)
+		r.createFailed(dexv1Client, status.Error(codes.DeadlineExceeded, "timeout"), now)
+		Expect(dexv1Client.Status.State).To(Equal(dexv1.PhaseFailed))
+		Expect(dexv1Client.Status.FailedAttempts).To(BeEquivalentTo(2))
+		Expect(dexv1Client.Status.NextRetryTime.Time).To(BeTemporally("==", now.Add(2*time.Second)))
+		Expect(retryDue(dexv1Client, now)).To(BeFalse())
+		Expect(retryDue(dexv1Client, now.Add(2*time.Second))).To(BeTrue())
+	})
+
+	It("should wait for a spec change after permanent errors", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Generation = 1
+		r.createFailed(dexv1Client, fmt.Errorf("client %q: %w", "grafana", dexapi.ErrAlreadyExists), now)
+		Expect(dexv1Client.Status.NextRetryTime).To(BeNil())
+		Expect(retryDue(dexv1Client, now.Add(time.Hour))).To(BeFalse())
+		dexv1Client.Generation = 2
+		Expect(retryDue(dexv1Client, now)).To(BeTrue())
+	})
+})
+
+var _ = Describe("summarizeConditions", func() {
+	It("should report an active client as ready", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Status.State = dexv1.PhaseActive
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionTrue, dexv1.ReasonCreated, "")
+		summarizeConditions(dexv1Client)
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionReady)).To(BeTrue())
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionDegraded)).To(BeFalse())
+	})
+
+	It("should report an active client which failed to update as degraded", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Status.State = dexv1.PhaseActiveDegraded
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonUpdateFailed, "unavailable")
+		summarizeConditions(dexv1Client)
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionReady)).To(BeTrue())
+		degraded := dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionDegraded)
+		Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(dexv1.ReasonUpdateFailed))
+	})
+
+	It("should explain why a client is not ready", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Status.State = dexv1.PhaseCreating
+		setClientCondition(dexv1Client, dexv1.ConditionSecretResolved, metav1.ConditionFalse, dexv1.ReasonSecretNotFound, "missing")
+		summarizeConditions(dexv1Client)
+		ready := dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionReady)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(dexv1.ReasonSecretNotFound))
+	})
+
+	It("should keep an active client ready while dex is unavailable", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Status.State = dexv1.PhaseActive
+		setClientCondition(dexv1Client, dexv1.ConditionDexAvailable, metav1.ConditionFalse, dexv1.ReasonDexUnavailable, "unavailable")
+		summarizeConditions(dexv1Client)
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionReady)).To(BeTrue())
+		degraded := dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionDegraded)
+		Expect(degraded.Reason).To(Equal(dexv1.ReasonDexUnavailable))
+	})
+
+	It("should not degrade clients of dex servers without drift detection", func() {
+		dexv1Client := &dexv1.Client{}
+		dexv1Client.Status.State = dexv1.PhaseActive
+		setClientCondition(dexv1Client, dexv1.ConditionDrifted, metav1.ConditionUnknown, dexv1.ReasonDriftDetectionUnsupported, "GetClient is not supported")
+		summarizeConditions(dexv1Client)
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionDegraded)).To(BeFalse())
+	})
+})
+
+var _ = Describe("deletionPolicy", func() {
+	It("should default to the policy of the operator", func() {
+		dexv1Client := &dexv1.Client{}
+		Expect((&ClientReconciler{}).deletionPolicy(dexv1Client)).To(Equal(dexv1.DeletionPolicyDelete))
+		r := &ClientReconciler{DeletionPolicy: dexv1.DeletionPolicyRetain}
+		Expect(r.deletionPolicy(dexv1Client)).To(Equal(dexv1.DeletionPolicyRetain))
+		dexv1Client.Spec.DeletionPolicy = dexv1.DeletionPolicyDelete
+		Expect(r.deletionPolicy(dexv1Client)).To(Equal(dexv1.DeletionPolicyDelete))
+	})
+})
+
+var _ = Describe("deletionTimedOut", func() {
+	It("should only give up once the finalizer timeout passed", func() {
+		now := time.Now()
+		deleted := metav1.NewTime(now.Add(-time.Hour))
+		dexv1Client := &dexv1.Client{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deleted}}
+
+		r := &ClientReconciler{}
+		Expect(r.deletionTimedOut(dexv1Client, now)).To(BeFalse())
+		r.FinalizerTimeout = 2 * time.Hour
+		Expect(r.deletionTimedOut(dexv1Client, now)).To(BeFalse())
+		r.FinalizerTimeout = 30 * time.Minute
+		Expect(r.deletionTimedOut(dexv1Client, now)).To(BeTrue())
+	})
+})
+
+var _ = Describe("immutableChanges", func() {
+	key := []byte("key")
+	newClient := func() *dexv1.Client {
+		public := false
+		return &dexv1.Client{
+			Status: dexv1.ClientStatus{SecretHash: hashSecret(key, "s3cr3t"), Public: &public},
+		}
+	}
+
+	It("should not report unchanged clients", func() {
+		Expect(immutableChanges(newClient(), key, "s3cr3t")).To(BeEmpty())
+	})
+
+	It("should report changed secrets and public flags", func() {
+		dexv1Client := newClient()
+		dexv1Client.Spec.Public = true
+		Expect(immutableChanges(dexv1Client, key, "other")).To(ConsistOf(fieldSecret, fieldPublic))
+	})
+
+	It("should not report fields which were never pushed", func() {
+		dexv1Client := &dexv1.Client{Spec: dexv1.ClientSpec{Public: true}}
+		Expect(immutableChanges(dexv1Client, key, "s3cr3t")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("rejectableChanges", func() {
+	It("should let the update strategy refuse every change of user provided secrets", func() {
+		dexv1Client := &dexv1.Client{Spec: dexv1.ClientSpec{Secret: "s3cr3t"}}
+		Expect(rejectableChanges(dexv1Client, []string{fieldSecret, fieldPublic})).To(ConsistOf(fieldSecret, fieldPublic))
+	})
+
+	It("should never refuse generated secrets", func() {
+		dexv1Client := &dexv1.Client{Spec: dexv1.ClientSpec{SecretGeneration: &dexv1.SecretGeneration{}}}
+		Expect(rejectableChanges(dexv1Client, []string{fieldSecret})).To(BeEmpty())
+		Expect(rejectableChanges(dexv1Client, []string{fieldSecret, fieldPublic})).To(ConsistOf(fieldPublic))
+	})
+})
+
+var _ = Describe("hashSecret", func() {
+	It("should depend on the key", func() {
+		Expect(hashSecret([]byte("key"), "s3cr3t")).To(Equal(hashSecret([]byte("key"), "s3cr3t")))
+		Expect(hashSecret([]byte("key"), "s3cr3t")).NotTo(Equal(hashSecret([]byte("other"), "s3cr3t")))
+		Expect(secretMatches([]byte("key"), hashSecret([]byte("key"), "s3cr3t"), "s3cr3t")).To(BeTrue())
+		Expect(secretMatches([]byte("other"), hashSecret([]byte("key"), "s3cr3t"), "s3cr3t")).To(BeFalse())
+	})
+
+	It("should create the key once", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme)
+		key, err := LoadSecretHashKey(context.Background(), c, "operator")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(HaveLen(secretHashKeyLength))
+		again, err := LoadSecretHashKey(context.Background(), c, "operator")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(key))
+	})
+
+	It("should not replace a lost key while hashes made with it exist", func() {
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"},
+			Status:     dexv1.ClientStatus{SecretHash: hashSecret([]byte("lost"), "s3cr3t")},
+		}
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, dexv1Client)
+		_, err := LoadSecretHashKey(context.Background(), c, "operator")
+		Expect(err).To(MatchError(ContainSubstring("Client team-a/grafana")))
+		Expect(apierrors.IsNotFound(c.Get(context.Background(),
+			k8stypes.NamespacedName{Name: SecretHashKeySecret, Namespace: "operator"}, &corev1.Secret{}))).To(BeTrue())
+	})
+})
+
+var _ = Describe("resolveClientID", func() {
+	newClient := func() *dexv1.Client {
+		return &dexv1.Client{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"}}
+	}
+
+	It("should derive the ID with the strategy", func() {
+		Expect((&ClientReconciler{}).resolveClientID(newClient())).To(Equal("grafana"))
+		r := &ClientReconciler{ClientIDStrategy: ClientIDStrategyNamespaceName}
+		Expect(r.resolveClientID(newClient())).To(Equal("team-a-grafana"))
+		r.ClientIDStrategy = ClientIDStrategyExplicit
+		_, err := r.resolveClientID(newClient())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should prefer spec.clientID", func() {
+		dexv1Client := newClient()
+		dexv1Client.Spec.ClientID = "team-a-grafana-prod"
+		r := &ClientReconciler{ClientIDStrategy: ClientIDStrategyExplicit}
+		Expect(r.resolveClientID(dexv1Client)).To(Equal("team-a-grafana-prod"))
+	})
+
+	It("should keep the owned ID", func() {
+		dexv1Client := newClient()
+		dexv1Client.Status.State = dexv1.PhaseActive
+		r := &ClientReconciler{ClientIDStrategy: ClientIDStrategyNamespaceName}
+		Expect(r.resolveClientID(dexv1Client)).To(Equal("grafana"))
+		dexv1Client.Spec.ClientID = "other"
+		_, err := r.resolveClientID(dexv1Client)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("claimClientID", func() {
+	newClient := func(name string, uid k8stypes.UID) *dexv1.Client {
+		return &dexv1.Client{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", UID: uid}}
+	}
+	claim := func(r *ClientReconciler, dexv1Client *dexv1.Client, id string) (string, bool) {
+		owner, claimed, err := r.claimClientID(context.Background(), dexv1Client, id)
+		Expect(err).NotTo(HaveOccurred())
+		return owner, claimed
+	}
+
+	It("should give the ID to the first client claiming it", func() {
+		first, second := newClient("first", "1"), newClient("second", "2")
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, first, second)
+		r := &ClientReconciler{Client: c, RetainedNamespace: "operator"}
+		ctx := context.Background()
+
+		owner, claimed := claim(r, first, "grafana")
+		Expect(owner).To(BeEmpty())
+		Expect(claimed).To(BeFalse())
+		owner, claimed = claim(r, first, "grafana")
+		Expect(owner).To(BeEmpty())
+		Expect(claimed).To(BeTrue())
+		owner, _ = claim(r, second, "grafana")
+		Expect(owner).To(Equal("Client team-a/first"))
+		owner, _ = claim(r, second, "prometheus")
+		Expect(owner).To(BeEmpty())
+
+		Expect(r.releaseClientID(ctx, second, "grafana")).To(Succeed())
+		owner, _ = claim(r, second, "grafana")
+		Expect(owner).To(Equal("Client team-a/first"))
+		Expect(r.releaseClientID(ctx, first, "grafana")).To(Succeed())
+		owner, claimed = claim(r, second, "grafana")
+		Expect(owner).To(BeEmpty())
+		Expect(claimed).To(BeFalse())
+	})
+
+	It("should take over claims of deleted clients", func() {
+		first, second := newClient("first", "1"), newClient("second", "2")
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, first, second)
+		r := &ClientReconciler{Client: c, RetainedNamespace: "operator"}
+		ctx := context.Background()
+
+		owner, _ := claim(r, first, "grafana")
+		Expect(owner).To(BeEmpty())
+		Expect(c.Delete(ctx, first)).To(Succeed())
+		owner, claimed := claim(r, second, "grafana")
+		Expect(owner).To(BeEmpty())
+		Expect(claimed).To(BeFalse())
+		owner, _ = claim(r, newClient("first", "3"), "grafana")
+		Expect(owner).To(Equal("Client team-a/second"))
+	})
+})
+
+var _ = Describe("reconcileClient", func() {
+	It("should report the deprecated secret once per generation", func() {
+		dexServer := dextest.NewServer()
+		defer dexServer.Stop()
+		dex, err := dexServer.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+		dexv1Client := &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a", UID: "1", Generation: 1},
+			Spec:       dexv1.ClientSpec{Name: "Grafana", Secret: "s3cr3t", RedirectURIs: []string{"https://grafana.example.com/callback"}},
+		}
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, dexv1Client)
+		recorder := record.NewFakeRecorder(20)
+		r := &ClientReconciler{Client: c, Log: logf.Log, DexClients: dexapi.NewPool(dex), Recorder: recorder, SecretHashKey: []byte("test")}
+		ctx := context.Background()
+		key := k8stypes.NamespacedName{Name: "grafana", Namespace: "team-a"}
+
+		for i := 0; i < 3; i++ {
+			latest := &dexv1.Client{}
+			Expect(c.Get(ctx, key, latest)).To(Succeed())
+			_, err := r.reconcileClient(ctx, logf.Log, latest)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(dexServer.Client("grafana")).NotTo(BeNil())
+		close(recorder.Events)
+		deprecated := 0
+		for event := range recorder.Events {
+			if strings.Contains(event, "DeprecatedSecret") {
+				deprecated++
+			}
+		}
+		Expect(deprecated).To(Equal(1))
+	})
+})
+
+var _ = Describe("createClient", func() {
+	var (
+		dexServer   *dextest.Server
+		dex         dexapi.Interface
+		dexv1Client *dexv1.Client
+		r           *ClientReconciler
+	)
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		dexServer = dextest.NewServer()
+		var err error
+		dex, err = dexServer.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+		dexv1Client = &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a", UID: "1"},
+			Spec:       dexv1.ClientSpec{Name: "Grafana", RedirectURIs: []string{"https://grafana.example.com/callback"}},
+		}
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, dexv1Client)
+		r = &ClientReconciler{Client: c, Log: logf.Log, RetainedNamespace: "operator"}
+	})
+
+	AfterEach(func() {
+		dexServer.Stop()
+	})
+
+	It("should take over the client an earlier attempt created", func() {
+		wanted := desiredClient(dexv1Client, "grafana", "secret")
+		Expect(r.createClient(ctx, dex, dexv1Client, wanted)).To(BeFalse())
+		// Writing the status failed, the next attempt finds its own client
+		Expect(r.createClient(ctx, dex, dexv1Client, wanted)).To(BeFalse())
+		Expect(dexServer.Client("grafana").GetSecret()).To(Equal("secret"))
+	})
+
+	It("should take over the client recorded in the status", func() {
+		r.RetainedNamespace = ""
+		dexServer.AddClient(&dexapi.Client{Id: "grafana", Secret: "secret", Name: "Grafana"})
+		dexv1Client.Status.ClientID = "grafana"
+		wanted := desiredClient(dexv1Client, "grafana", "secret")
+		Expect(r.createClient(ctx, dex, dexv1Client, wanted)).To(BeFalse())
+	})
+
+	It("should requeue recreated clients for drift detection", func() {
+		dexServer.AddClient(&dexapi.Client{Id: "grafana", Secret: "old", Name: "Grafana"})
+		dexv1Client.Status.State = dexv1.PhaseActive
+		r.DriftInterval = time.Hour
+		r.Recorder = record.NewFakeRecorder(10)
+		wanted := desiredClient(dexv1Client, "grafana", "secret")
+		result, err := r.recreateClient(ctx, dex, dexv1Client, wanted, []string{fieldSecret})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+		Expect(dexServer.Client("grafana").GetSecret()).To(Equal("secret"))
+	})
+
+	It("should fail and release the claim for clients it did not create", func() {
+		dexServer.AddClient(&dexapi.Client{Id: "grafana", Secret: "other", Name: "Other"})
+		wanted := desiredClient(dexv1Client, "grafana", "secret")
+		_, err := r.createClient(ctx, dex, dexv1Client, wanted)
+		Expect(errors.Is(err, dexapi.ErrAlreadyExists)).To(BeTrue())
+		_, err = r.createClient(ctx, dex, dexv1Client, wanted)
+		Expect(errors.Is(err, dexapi.ErrAlreadyExists)).To(BeTrue())
+		Expect(dexServer.Client("grafana").GetSecret()).To(Equal("other"))
+	})
 })
 
 func getResourceFunc(ctx context.Context, key client.ObjectKey, obj runtime.Object) func() error {
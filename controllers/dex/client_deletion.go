@@ -0,0 +1,134 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// Outcomes of the deletion of a client, used as label of client_deletions_total
+const (
+	deletionDeleted  = "deleted"
+	deletionNotFound = "not_found"
+	deletionRetained = "retained"
+	deletionForced   = "forced"
+	deletionTimedOut = "timed_out"
+)
+
+// finalizeClient deletes the client from dex and removes the finalizer. A client
+// already gone from dex counts as deleted. The finalizer is also removed without
+// deleting the client when the deletion policy retains it, the force-delete
+// annotation is set or the deletion did not succeed within the finalizer timeout.
+func (r *ClientReconciler) finalizeClient(ctx context.Context, log logr.Logger, dexv1Client dexv1.ClientObject, finalizer string) (ctrl.Result, error) {
+	status := dexv1Client.GetClientStatus()
+	r.Recorder.Eventf(dexv1Client, "Normal", "ClientDeletion", "client %s", dexv1Client.GetName())
+	// A client ID owned by another Client is never deleted
+	id := ownedClientID(dexv1Client)
+	if status.State != dexv1.PhaseFailed && id != "" { // It's a failed client, just delete it.
+		status.State = dexv1.PhaseDeleting
+		var outcome string
+		var err error
+		waiting := false
+		switch {
+		case r.deletionPolicy(dexv1Client) == dexv1.DeletionPolicyRetain:
+			if err = r.retainClient(ctx, log, dexv1Client, id); err == nil {
+				outcome = deletionRetained
+			}
+		case r.forceDelete(dexv1Client, id):
+			outcome = deletionForced
+		default:
+			if err = r.dexAvailable(dexv1Client); err != nil {
+				waiting = true
+				break
+			}
+			outcome, err = r.deleteFromDex(ctx, log, dexv1Client, id)
+			waiting = r.notConnected(dexv1Client, err)
+		}
+
+		switch {
+		case outcome != "":
+			clientDeletions.WithLabelValues(outcome).Inc()
+		case r.deletionTimedOut(dexv1Client, time.Now()):
+			log.Error(err, "Giving up deleting the client from dex", "timeout", r.FinalizerTimeout)
+			r.Recorder.Eventf(dexv1Client, "Warning", "ClientDeletionTimeout", "client %s: %q was not deleted from dex within %s: %s",
+				dexv1Client.GetName(), id, r.FinalizerTimeout, err.Error())
+			clientDeletions.WithLabelValues(deletionTimedOut).Inc()
+		case waiting:
+			return r.waitForDex(ctx, log, dexv1Client, err)
+		default:
+			clientDeletionFailures.Inc()
+			status.Message = err.Error()
+			if err := r.saveClient(ctx, dexv1Client); err != nil {
+				r.Recorder.Eventf(dexv1Client, "Error", "ClientDeletion", "client %s: %s", dexv1Client.GetName(), err.Error())
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	// A claim left behind is taken over once the client is gone
+	if claimed, err := r.resolveClientID(dexv1Client); err == nil {
+		if err := r.releaseClientID(ctx, dexv1Client, claimed); err != nil {
+			log.Error(err, "unable to release the client ID", "client ID", claimed)
+		}
+	}
+	// remove our finalizer from the list and update it.
+	return ctrl.Result{}, r.patchClient(ctx, dexv1Client, func() {
+		dexv1Client.SetFinalizers(removeString(dexv1Client.GetFinalizers(), finalizer))
+	})
+}
+
+// forceDelete returns true when the force-delete annotation lets the client be
+// deleted without deleting it from dex, e.g. when dex is gone for good
+func (r *ClientReconciler) forceDelete(dexv1Client dexv1.ClientObject, id string) bool {
+	if _, ok := dexv1Client.GetAnnotations()[dexv1.ForceDeleteAnnotation]; !ok {
+		return false
+	}
+	r.Log.Info("Force deleting client, it is left in dex", "client", dexv1Client.GetName(), "client ID", id)
+	r.Recorder.Eventf(dexv1Client, "Warning", "ClientDeletionForced", "client %s: %q is left in dex as %s is set",
+		dexv1Client.GetName(), id, dexv1.ForceDeleteAnnotation)
+	return true
+}
+
+// deleteFromDex deletes the client from dex, a client which is already gone counts
+// as deleted. It returns the outcome or the error when the deletion failed.
+func (r *ClientReconciler) deleteFromDex(ctx context.Context, log logr.Logger, dexv1Client dexv1.ClientObject, id string) (string, error) {
+	err := r.deleteClient(ctx, dexv1Client, id)
+	switch {
+	case err == nil:
+		return deletionDeleted, nil
+	case errors.Is(err, dexapi.ErrNotFound):
+		log.Info("Client already gone from dex", "client ID", id)
+		r.Recorder.Eventf(dexv1Client, "Normal", "ClientDeletion", "client %s: %q was already gone from dex", dexv1Client.GetName(), id)
+		return deletionNotFound, nil
+	}
+	return "", err
+}
+
+// deletionTimedOut returns true when the client is being deleted for longer than
+// the finalizer timeout
+func (r *ClientReconciler) deletionTimedOut(dexv1Client dexv1.ClientObject, now time.Time) bool {
+	deleted := dexv1Client.GetDeletionTimestamp()
+	return r.FinalizerTimeout > 0 && deleted != nil && now.Sub(deleted.Time) >= r.FinalizerTimeout
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// dexServerIndexKey indexes clients by the name of the DexServer they reference
+const dexServerIndexKey = "spec.dexServerRef"
+
+// dexServerStatusIndexKey indexes clients by the name of the DexServer they were
+// created in
+const dexServerStatusIndexKey = "status.dexServer"
+
+// errDexServerChanged is returned when the DexServer of a created client is changed
+var errDexServerChanged = errors.New("dexServerRef can not be changed once the client is created")
+
+// dexServerRefName returns the name of the DexServer referenced by the client, empty
+// for the default dex instance
+func dexServerRefName(dexv1Client dexv1.ClientObject) string {
+	if ref := dexv1Client.GetClientSpec().DexServerRef; ref != nil {
+		return ref.Name
+	}
+	return ""
+}
+
+// dexServer returns the connection to the dex server of the client and records the
+// server in the status. A client stays in the server it was created in.
+func (r *ClientReconciler) dexServer(dexv1Client dexv1.ClientObject) (dexapi.Interface, error) {
+	status := dexv1Client.GetClientStatus()
+	name := dexServerRefName(dexv1Client)
+	if ownedClientID(dexv1Client) != "" && name != status.DexServer {
+		return nil, fmt.Errorf("%w, it is %q", errDexServerChanged, status.DexServer)
+	}
+	status.DexServer = name
+	return r.DexClients.Get(name)
+}
+
+// deleteClient deletes the client from the dex server it was created in
+func (r *ClientReconciler) deleteClient(ctx context.Context, dexv1Client dexv1.ClientObject, id string) error {
+	dex, err := r.DexClients.Get(dexv1Client.GetClientStatus().DexServer)
+	if err != nil {
+		return err
+	}
+	return dex.DeleteClient(ctx, id)
+}
+
+// dexAvailable sets the DexAvailable condition from the last probe of the dex server
+// of the client, it returns the error of the probe while the server is unavailable
+func (r *ClientReconciler) dexAvailable(dexv1Client dexv1.ClientObject) error {
+	if r.Prober == nil {
+		return nil
+	}
+	if err := r.Prober.Unavailable(dexv1Client.GetClientStatus().DexServer); err != nil {
+		r.setDexUnavailable(dexv1Client, dexv1.ReasonDexUnavailable, err)
+		return err
+	}
+	setClientCondition(dexv1Client, dexv1.ConditionDexAvailable, metav1.ConditionTrue, dexv1.ReasonDexAvailable, "")
+	return nil
+}
+
+// notConnected sets the DexAvailable condition of a client whose dex server has no
+// connection yet, it returns false for other errors
+func (r *ClientReconciler) notConnected(dexv1Client dexv1.ClientObject, err error) bool {
+	if !errors.Is(err, dexapi.ErrNotConnected) {
+		return false
+	}
+	r.setDexUnavailable(dexv1Client, dexv1.ReasonWaitingForDex, err)
+	return true
+}
+
+// setDexUnavailable sets the DexAvailable condition to False, the DexUnavailable event
+// is only recorded when the client starts waiting or waits for another reason
+func (r *ClientReconciler) setDexUnavailable(dexv1Client dexv1.ClientObject, reason string, err error) {
+	previous := dexv1.FindCondition(dexv1Client.GetClientStatus().Conditions, dexv1.ConditionDexAvailable)
+	changed := previous == nil || previous.Status != metav1.ConditionFalse || previous.Reason != reason
+	setClientCondition(dexv1Client, dexv1.ConditionDexAvailable, metav1.ConditionFalse, reason, err.Error())
+	if changed {
+		r.Recorder.Eventf(dexv1Client, "Warning", "DexUnavailable", "client %s: %s", dexv1Client.GetName(), err.Error())
+	}
+}
+
+// waitForDex records that the client waits for its unavailable dex server and
+// requeues it, the client keeps its state
+func (r *ClientReconciler) waitForDex(ctx context.Context, log logr.Logger, dexv1Client dexv1.ClientObject, err error) (ctrl.Result, error) {
+	log.Info("Waiting for dex", "reason", err.Error())
+	dexv1Client.GetClientStatus().Message = err.Error()
+	if err := r.saveClient(ctx, dexv1Client); err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.Prober == nil {
+		return ctrl.Result{RequeueAfter: r.RetryBaseDelay}, nil
+	}
+	return ctrl.Result{RequeueAfter: r.Prober.Interval}, nil
+}
+
+// indexDexServerRef is the field indexer for dexServerIndexKey
+func indexDexServerRef(o runtime.Object) []string {
+	name := dexServerRefName(o.(dexv1.ClientObject))
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+// indexDexServerStatus is the field indexer for dexServerStatusIndexKey
+func indexDexServerStatus(o runtime.Object) []string {
+	name := o.(dexv1.ClientObject).GetClientStatus().DexServer
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+// clientsForDexServer maps a DexServer to the Clients referencing it
+func (r *ClientReconciler) clientsForDexServer(o handler.MapObject) []reconcile.Request {
+	clients := &dexv1.ClientList{}
+	if err := r.List(context.Background(), clients, client.MatchingFields{dexServerIndexKey: o.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list clients for dex server", "dexserver", o.Meta.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clients.Items))
+	for _, item := range clients.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name, Namespace: item.Namespace},
+		})
+	}
+	return requests
+}
+
+// clusterClientsForDexServer maps a DexServer to the ClusterClients referencing it
+func (r *ClusterClientReconciler) clusterClientsForDexServer(o handler.MapObject) []reconcile.Request {
+	clusterClients := &dexv1.ClusterClientList{}
+	if err := r.List(context.Background(), clusterClients, client.MatchingFields{dexServerIndexKey: o.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list cluster clients for dex server", "dexserver", o.Meta.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clusterClients.Items))
+	for _, item := range clusterClients.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name},
+		})
+	}
+	return requests
+}
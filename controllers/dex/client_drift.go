@@ -0,0 +1,138 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// Fields reported as drifted, fieldMissing is used when the whole client is gone
+const (
+	fieldMissing      = "missing"
+	fieldSecret       = "secret"
+	fieldPublic       = "public"
+	fieldName         = "name"
+	fieldLogoURL      = "logoURL"
+	fieldRedirectURIs = "redirectURIs"
+	fieldTrustedPeers = "trustedPeers"
+)
+
+// reconcileDrift compares the live dex client with the wanted one and corrects any difference
+func (r *ClientReconciler) reconcileDrift(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest) error {
+	log := r.Log.WithValues("client", dexv1Client.GetName())
+	live, err := dex.GetClient(ctx, wanted.ID)
+	// Old dex versions can not return a single client, drift detection is skipped
+	if errors.Is(err, dexapi.ErrUnsupported) {
+		setClientCondition(dexv1Client, dexv1.ConditionDrifted, metav1.ConditionUnknown, dexv1.ReasonDriftDetectionUnsupported, err.Error())
+		return nil
+	}
+	if errors.Is(err, dexapi.ErrNotFound) {
+		log.Info("Client missing in dex, creating it")
+		if _, err := dex.CreateClient(ctx, wanted); err != nil {
+			return err
+		}
+		r.recordDrift(dexv1Client, dexv1.ReasonClientMissing, []string{fieldMissing})
+		return nil
+	}
+	if err != nil {
+		setClientCondition(dexv1Client, dexv1.ConditionDrifted, metav1.ConditionUnknown, dexv1.ReasonDriftCheckFailed, err.Error())
+		return err
+	}
+
+	drifted := clientDrift(wanted, live)
+	if len(drifted) == 0 {
+		setClientCondition(dexv1Client, dexv1.ConditionDrifted, metav1.ConditionFalse, dexv1.ReasonInSync, "")
+		return nil
+	}
+	log.Info("Client drifted, correcting it", "fields", drifted)
+	if immutableDrift(drifted) {
+		err = dex.RecreateClient(ctx, wanted)
+	} else {
+		err = dex.UpdateClient(ctx, wanted.UpdateRequest())
+	}
+	if err != nil {
+		setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonDriftCorrectionFailed, err.Error())
+		return err
+	}
+	r.recordDrift(dexv1Client, dexv1.ReasonDriftCorrected, drifted)
+	return nil
+}
+
+// recordDrift reports corrected fields in the Drifted condition, events and metrics
+func (r *ClientReconciler) recordDrift(dexv1Client dexv1.ClientObject, reason string, fields []string) {
+	for _, field := range fields {
+		clientDriftCorrections.WithLabelValues(field).Inc()
+	}
+	message := "corrected " + strings.Join(fields, ", ")
+	setClientCondition(dexv1Client, dexv1.ConditionDrifted, metav1.ConditionTrue, reason, message)
+	r.Recorder.Eventf(dexv1Client, "Warning", "ClientDrift", "client %s: %s", dexv1Client.GetName(), message)
+}
+
+// clientDrift returns the fields in which the live client differs from the wanted one,
+// the secret is only compared when one is wanted, e.g. public clients may have none.
+func clientDrift(wanted dexapi.CreateClientRequest, live *dexapi.Client) []string {
+	var drifted []string
+	if wanted.Secret != "" && live.GetSecret() != wanted.Secret {
+		drifted = append(drifted, fieldSecret)
+	}
+	if live.GetPublic() != wanted.Public {
+		drifted = append(drifted, fieldPublic)
+	}
+	if live.GetName() != wanted.Name {
+		drifted = append(drifted, fieldName)
+	}
+	if live.GetLogoUrl() != wanted.LogoURL {
+		drifted = append(drifted, fieldLogoURL)
+	}
+	if !sameStrings(live.GetRedirectUris(), wanted.RedirectURIs) {
+		drifted = append(drifted, fieldRedirectURIs)
+	}
+	if !sameStrings(live.GetTrustedPeers(), wanted.TrustedPeers) {
+		drifted = append(drifted, fieldTrustedPeers)
+	}
+	return drifted
+}
+
+// immutableDrift returns true when drifted fields can only be corrected by recreating the client
+func immutableDrift(drifted []string) bool {
+	return containsString(drifted, fieldSecret) || containsString(drifted, fieldPublic)
+}
+
+// sameStrings compares two string slices ignoring order
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+// Strategies deriving the dex client ID of Clients without spec.clientID
+const (
+	// ClientIDStrategyName uses metadata.name, IDs of Clients in different namespaces can collide
+	ClientIDStrategyName = "name"
+	// ClientIDStrategyNamespaceName uses <metadata.namespace>-<metadata.name>, ClusterClients
+	// use their name
+	ClientIDStrategyNamespaceName = "namespace-name"
+	// ClientIDStrategyExplicit requires spec.clientID to be set
+	ClientIDStrategyExplicit = "explicit"
+)
+
+// ClientIDStrategies are the supported client ID strategies
+var ClientIDStrategies = []string{ClientIDStrategyName, ClientIDStrategyNamespaceName, ClientIDStrategyExplicit}
+
+// IsClientIDStrategy returns true for supported client ID strategies
+func IsClientIDStrategy(strategy string) bool {
+	return containsString(ClientIDStrategies, strategy)
+}
+
+// clientIDIndexKey indexes Clients by the dex client ID they own
+const clientIDIndexKey = "status.clientID"
+
+const (
+	// ClientOwnersConfigMap is the ConfigMap the claims on dex client IDs are recorded in
+	ClientOwnersConfigMap = "dex-operator-client-owners"
+	// clientOwnersKey is the key of the claims in the ConfigMap
+	clientOwnersKey = "owners.json"
+)
+
+// clientOwner records the Client or ClusterClient which claimed a dex client ID
+type clientOwner struct {
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace,omitempty"`
+	Name      string       `json:"name"`
+	UID       k8stypes.UID `json:"uid"`
+}
+
+// String returns the kind and name of the owner
+func (c clientOwner) String() string {
+	if c.Namespace == "" {
+		return c.Kind + " " + c.Name
+	}
+	return c.Kind + " " + c.Namespace + "/" + c.Name
+}
+
+// ownedClientID returns the dex client ID owned by the Client, empty when it did not
+// create or adopt a client yet.
+func ownedClientID(dexv1Client dexv1.ClientObject) string {
+	status := dexv1Client.GetClientStatus()
+	if status.ClientID != "" {
+		return status.ClientID
+	}
+	// Clients created before the ID was recorded in the status used their name
+	if status.State == dexv1.PhaseActive || status.State == dexv1.PhaseActiveDegraded {
+		return dexv1Client.GetName()
+	}
+	return ""
+}
+
+// resolveClientID returns the dex client ID of the Client, the ID never changes once
+// the Client owns it.
+func (r *ClientReconciler) resolveClientID(dexv1Client dexv1.ClientObject) (string, error) {
+	spec := dexv1Client.GetClientSpec()
+	if id := ownedClientID(dexv1Client); id != "" {
+		if spec.ClientID != "" && spec.ClientID != id {
+			return "", fmt.Errorf("clientID can not be changed from %q to %q", id, spec.ClientID)
+		}
+		return id, nil
+	}
+	if spec.ClientID != "" {
+		return spec.ClientID, nil
+	}
+	switch r.ClientIDStrategy {
+	case ClientIDStrategyNamespaceName:
+		if dexv1Client.GetNamespace() == "" {
+			return dexv1Client.GetName(), nil
+		}
+		return fmt.Sprintf("%s-%s", dexv1Client.GetNamespace(), dexv1Client.GetName()), nil
+	case ClientIDStrategyExplicit:
+		return "", errors.New("spec.clientID is required by the explicit client ID strategy")
+	default:
+		return dexv1Client.GetName(), nil
+	}
+}
+
+// clientIDOwner describes another Client or ClusterClient owning the dex client ID in
+// the dex server of the client, empty when there is none.
+func (r *ClientReconciler) clientIDOwner(ctx context.Context, dexv1Client dexv1.ClientObject, id string) (string, error) {
+	value := clientIDIndexValue(dexv1Client.GetClientStatus().DexServer, id)
+	clients := &dexv1.ClientList{}
+	if err := r.List(ctx, clients, client.MatchingFields{clientIDIndexKey: value}); err != nil {
+		return "", fmt.Errorf("unable to list the owners of client ID %q: %w", id, err)
+	}
+	for _, item := range clients.Items {
+		if item.UID != dexv1Client.GetUID() {
+			return "Client " + k8stypes.NamespacedName{Name: item.Name, Namespace: item.Namespace}.String(), nil
+		}
+	}
+	clusterClients := &dexv1.ClusterClientList{}
+	if err := r.List(ctx, clusterClients, client.MatchingFields{clientIDIndexKey: value}); err != nil {
+		return "", fmt.Errorf("unable to list the owners of client ID %q: %w", id, err)
+	}
+	for _, item := range clusterClients.Items {
+		if item.UID != dexv1Client.GetUID() {
+			return "ClusterClient " + item.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// claimClientID records the client as the owner of the dex client ID before it is
+// created in dex, it returns the owner when another existing Client or ClusterClient
+// claimed the ID first. Unlike clientIDOwner it reads the record uncached, so of two
+// clients created together only one gets the ID, the other one fails with a conflict
+// and finds the claim when it is retried. claimed is true when the client already
+// held the claim, an earlier attempt may then have created the client in dex.
+func (r *ClientReconciler) claimClientID(ctx context.Context, dexv1Client dexv1.ClientObject, id string) (owner string, claimed bool, err error) {
+	if r.RetainedNamespace == "" {
+		return "", false, nil
+	}
+	key := clientIDIndexValue(dexv1Client.GetClientStatus().DexServer, id)
+	owners := map[string]clientOwner{}
+	configMap, err := r.readRecords(ctx, ClientOwnersConfigMap, clientOwnersKey, &owners)
+	if err != nil {
+		return "", false, err
+	}
+	if claim, ok := owners[key]; ok {
+		if claim.UID == dexv1Client.GetUID() {
+			return "", true, nil
+		}
+		exists, err := r.ownerExists(ctx, claim)
+		if err != nil {
+			return "", false, err
+		}
+		if exists {
+			return claim.String(), false, nil
+		}
+	}
+	owners[key] = clientOwner{
+		Kind:      clientKind(dexv1Client),
+		Namespace: dexv1Client.GetNamespace(),
+		Name:      dexv1Client.GetName(),
+		UID:       dexv1Client.GetUID(),
+	}
+	if err := r.writeRecords(ctx, configMap, ClientOwnersConfigMap, clientOwnersKey, owners); err != nil {
+		return "", false, fmt.Errorf("unable to claim client ID %q: %w", id, err)
+	}
+	return "", false, nil
+}
+
+// releaseClientID removes the claim of the client on the dex client ID
+func (r *ClientReconciler) releaseClientID(ctx context.Context, dexv1Client dexv1.ClientObject, id string) error {
+	if r.RetainedNamespace == "" {
+		return nil
+	}
+	key := clientIDIndexValue(dexv1Client.GetClientStatus().DexServer, id)
+	owners := map[string]clientOwner{}
+	configMap, err := r.readRecords(ctx, ClientOwnersConfigMap, clientOwnersKey, &owners)
+	if err != nil {
+		return err
+	}
+	if owner, ok := owners[key]; !ok || owner.UID != dexv1Client.GetUID() {
+		return nil
+	}
+	delete(owners, key)
+	return r.writeRecords(ctx, configMap, ClientOwnersConfigMap, clientOwnersKey, owners)
+}
+
+// ownerExists returns true while the Client or ClusterClient of a claim exists, a
+// claim of a deleted one is taken over
+func (r *ClientReconciler) ownerExists(ctx context.Context, owner clientOwner) (bool, error) {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	var obj dexv1.ClientObject = &dexv1.Client{}
+	if owner.Kind == "ClusterClient" {
+		obj = &dexv1.ClusterClient{}
+	}
+	err := reader.Get(ctx, k8stypes.NamespacedName{Name: owner.Name, Namespace: owner.Namespace}, obj)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return obj.GetUID() == owner.UID, nil
+}
+
+// indexClientID is the field indexer for clientIDIndexKey
+func indexClientID(o runtime.Object) []string {
+	dexv1Client := o.(dexv1.ClientObject)
+	id := ownedClientID(dexv1Client)
+	if id == "" {
+		return nil
+	}
+	return []string{clientIDIndexValue(dexv1Client.GetClientStatus().DexServer, id)}
+}
+
+// clientIDIndexValue returns the value indexed for a client ID, IDs are only unique
+// within a dex server
+func clientIDIndexValue(server, id string) string {
+	if server == "" {
+		return id
+	}
+	return server + "/" + id
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+const (
+	// RetainedClientsConfigMap is the ConfigMap retained dex clients are recorded in
+	RetainedClientsConfigMap = "dex-operator-retained-clients"
+	// retainedClientsKey is the key of the records in the ConfigMap
+	retainedClientsKey = "clients.json"
+)
+
+// DeletionPolicies are the supported deletion policies
+var DeletionPolicies = []string{dexv1.DeletionPolicyDelete, dexv1.DeletionPolicyRetain}
+
+// IsDeletionPolicy returns true for supported deletion policies
+func IsDeletionPolicy(policy string) bool {
+	return containsString(DeletionPolicies, policy)
+}
+
+// retainedClient records the Client a dex client was retained by
+type retainedClient struct {
+	Kind       string      `json:"kind"`
+	Namespace  string      `json:"namespace,omitempty"`
+	Name       string      `json:"name"`
+	RetainedAt metav1.Time `json:"retainedAt"`
+}
+
+// String returns the kind and name of the Client the dex client was retained by
+func (c retainedClient) String() string {
+	if c.Namespace == "" {
+		return c.Kind + " " + c.Name
+	}
+	return c.Kind + " " + c.Namespace + "/" + c.Name
+}
+
+// deletionPolicy returns the deletion policy of the client, the default of the
+// operator when the spec has none
+func (r *ClientReconciler) deletionPolicy(dexv1Client dexv1.ClientObject) string {
+	if policy := dexv1Client.GetClientSpec().DeletionPolicy; policy != "" {
+		return policy
+	}
+	if r.DeletionPolicy != "" {
+		return r.DeletionPolicy
+	}
+	return dexv1.DeletionPolicyDelete
+}
+
+// retainClient leaves the client in dex and records it, so a new Client with the
+// same ID adopts it regardless of its adoption policy
+func (r *ClientReconciler) retainClient(ctx context.Context, log logr.Logger, dexv1Client dexv1.ClientObject, id string) error {
+	key := clientIDIndexValue(dexv1Client.GetClientStatus().DexServer, id)
+	err := r.updateRetained(ctx, func(retained map[string]retainedClient) bool {
+		retained[key] = retainedClient{
+			Kind:       clientKind(dexv1Client),
+			Namespace:  dexv1Client.GetNamespace(),
+			Name:       dexv1Client.GetName(),
+			RetainedAt: metav1.Now(),
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("unable to record the retained client: %w", err)
+	}
+	log.Info("Retaining client in dex", "client ID", id)
+	r.Recorder.Eventf(dexv1Client, "Normal", "ClientRetained", "client %s: %q is left in dex as the deletion policy is %s",
+		dexv1Client.GetName(), id, dexv1.DeletionPolicyRetain)
+	return nil
+}
+
+// retainedBy returns the record of a retained client, nil when the client was not
+// retained
+func (r *ClientReconciler) retainedBy(ctx context.Context, server, id string) (*retainedClient, error) {
+	retained, _, err := r.readRetained(ctx)
+	if err != nil {
+		return nil, err
+	}
+	record, ok := retained[clientIDIndexValue(server, id)]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// forgetRetained removes the record of a retained client once it was adopted
+func (r *ClientReconciler) forgetRetained(ctx context.Context, server, id string) error {
+	key := clientIDIndexValue(server, id)
+	return r.updateRetained(ctx, func(retained map[string]retainedClient) bool {
+		if _, ok := retained[key]; !ok {
+			return false
+		}
+		delete(retained, key)
+		return true
+	})
+}
+
+// readRetained returns the records of retained clients and their ConfigMap, which
+// is nil when it does not exist. Nothing is recorded without RetainedNamespace.
+func (r *ClientReconciler) readRetained(ctx context.Context) (map[string]retainedClient, *corev1.ConfigMap, error) {
+	retained := map[string]retainedClient{}
+	configMap, err := r.readRecords(ctx, RetainedClientsConfigMap, retainedClientsKey, &retained)
+	if err != nil {
+		return nil, nil, err
+	}
+	return retained, configMap, nil
+}
+
+// updateRetained changes the records of retained clients, the ConfigMap is only
+// written when mutate returns true. Concurrent changes fail with a conflict.
+func (r *ClientReconciler) updateRetained(ctx context.Context, mutate func(map[string]retainedClient) bool) error {
+	if r.RetainedNamespace == "" {
+		return nil
+	}
+	retained, configMap, err := r.readRetained(ctx)
+	if err != nil {
+		return err
+	}
+	if !mutate(retained) {
+		return nil
+	}
+	return r.writeRecords(ctx, configMap, RetainedClientsConfigMap, retainedClientsKey, retained)
+}
+
+// readRecords decodes the JSON records under key of the named ConfigMap in the
+// RetainedNamespace into records and returns the ConfigMap, which is nil when it
+// does not exist or no namespace is configured
+func (r *ClientReconciler) readRecords(ctx context.Context, name, key string, records interface{}) (*corev1.ConfigMap, error) {
+	if r.RetainedNamespace == "" {
+		return nil, nil
+	}
+	// The ConfigMap is read uncached, the operator does not watch ConfigMaps
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, k8stypes.NamespacedName{Name: name, Namespace: r.RetainedNamespace}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if data := configMap.Data[key]; data != "" {
+		if err := json.Unmarshal([]byte(data), records); err != nil {
+			return nil, fmt.Errorf("invalid %s in ConfigMap %s: %w", key, name, err)
+		}
+	}
+	return configMap, nil
+}
+
+// writeRecords writes the records under key of the ConfigMap read by readRecords,
+// it is created when configMap is nil. Concurrent changes fail with a conflict.
+func (r *ClientReconciler) writeRecords(ctx context.Context, configMap *corev1.ConfigMap, name, key string, records interface{}) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.RetainedNamespace},
+			Data:       map[string]string{key: string(data)},
+		}
+		return r.Create(ctx, configMap)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[key] = string(data)
+	return r.Update(ctx, configMap)
+}
+
+// clientKind returns the kind of the client for records and messages
+func clientKind(dexv1Client dexv1.ClientObject) string {
+	if _, ok := dexv1Client.(*dexv1.ClusterClient); ok {
+		return "ClusterClient"
+	}
+	return "Client"
+}
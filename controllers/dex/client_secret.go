@@ -0,0 +1,308 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+const (
+	// secretRefIndexKey indexes Clients by the namespace/name of the Secret they reference
+	secretRefIndexKey = "spec.secretRef"
+	// generatedSecretKey is the key of the secret in generated Secrets
+	generatedSecretKey = "clientSecret"
+)
+
+var secretCharsets = map[string]string{
+	dexv1.CharsetAlphanumeric: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+	dexv1.CharsetHex:          "0123456789abcdef",
+	dexv1.CharsetURLSafe:      "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_",
+}
+
+// secretRefName returns the namespaced name of the Secret referenced by the client,
+// the Secret must live in the namespace of a Client while a ClusterClient names it.
+func secretRefName(dexv1Client dexv1.ClientObject) (k8stypes.NamespacedName, error) {
+	ref := dexv1Client.GetClientSpec().SecretRef
+	if dexv1Client.GetNamespace() == "" {
+		if ref.Namespace == "" {
+			return k8stypes.NamespacedName{}, errors.New("secretRef namespace is required for cluster scoped clients")
+		}
+		return k8stypes.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, nil
+	}
+	if ref.Namespace != "" && ref.Namespace != dexv1Client.GetNamespace() {
+		return k8stypes.NamespacedName{}, fmt.Errorf("secretRef namespace %q differs from the client namespace", ref.Namespace)
+	}
+	return k8stypes.NamespacedName{Name: ref.Name, Namespace: dexv1Client.GetNamespace()}, nil
+}
+
+// generatedSecretName returns the name of the Secret holding a generated client secret,
+// it lives in the namespace of a Client or the one given by a ClusterClient.
+func generatedSecretName(dexv1Client dexv1.ClientObject) (k8stypes.NamespacedName, error) {
+	namespace := dexv1Client.GetNamespace()
+	if namespace == "" {
+		namespace = dexv1Client.GetClientSpec().SecretGeneration.Namespace
+	}
+	if namespace == "" {
+		return k8stypes.NamespacedName{}, errors.New("secretGeneration namespace is required for cluster scoped clients")
+	}
+	return k8stypes.NamespacedName{
+		Name:      fmt.Sprintf("client-secret-%s", dexv1Client.GetName()),
+		Namespace: namespace,
+	}, nil
+}
+
+// resolveClientSecret returns the oidc secret of the client, read from the referenced
+// Secret, the generated Secret or taken from the deprecated inline field. Errors never
+// contain the secret value.
+func resolveClientSecret(ctx context.Context, c client.Reader, dexv1Client dexv1.ClientObject) (string, error) {
+	spec := dexv1Client.GetClientSpec()
+	switch {
+	case spec.SecretRef != nil:
+		namespacedName, err := secretRefName(dexv1Client)
+		if err != nil {
+			return "", err
+		}
+		return readSecretKey(ctx, c, namespacedName, spec.SecretRef.Key)
+	case spec.SecretGeneration != nil:
+		namespacedName, err := generatedSecretName(dexv1Client)
+		if err != nil {
+			return "", err
+		}
+		return readSecretKey(ctx, c, namespacedName, generatedSecretKey)
+	default:
+		return spec.Secret, nil
+	}
+}
+
+func readSecretKey(ctx context.Context, c client.Reader, namespacedName k8stypes.NamespacedName, key string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, namespacedName, secret); err != nil {
+		return "", fmt.Errorf("unable to get secret %s: %w", namespacedName, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("secret %s has no key %q", namespacedName, key)
+	}
+	return string(value), nil
+}
+
+// reconcileGeneratedSecret returns the generated client secret, creating the owned
+// Secret holding it and the client ID when it does not exist.
+func (r *ClientReconciler) reconcileGeneratedSecret(ctx context.Context, dexv1Client dexv1.ClientObject, id string) (string, error) {
+	spec := dexv1Client.GetClientSpec()
+	namespacedName, err := generatedSecretName(dexv1Client)
+	if err != nil {
+		return "", err
+	}
+	value, err := readSecretKey(ctx, r, namespacedName, generatedSecretKey)
+	if err == nil {
+		return value, nil
+	}
+	if !apierrors.IsNotFound(errors.Unwrap(err)) {
+		return "", err
+	}
+	value, err = generateSecret(spec.SecretGeneration)
+	if err != nil {
+		return "", err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Data: map[string][]byte{
+			"clientId":         []byte(id),
+			generatedSecretKey: []byte(value),
+		},
+	}
+	if err := ctrl.SetControllerReference(dexv1Client, secret, r.Scheme); err != nil {
+		return "", err
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return "", err
+	}
+	r.Recorder.Eventf(dexv1Client, "Normal", "SecretGenerated", "client %s: secret %s", dexv1Client.GetName(), namespacedName.Name)
+	return value, nil
+}
+
+// rotatable returns true when the operator owns the secret of the client
+func rotatable(dexv1Client dexv1.ClientObject) bool {
+	spec := dexv1Client.GetClientSpec()
+	return spec.SecretRef == nil && spec.SecretGeneration != nil
+}
+
+// rotationDue returns true when the secret of the client should be rotated, either
+// on demand through the annotation or because the rotation interval passed.
+func rotationDue(dexv1Client dexv1.ClientObject, now time.Time) bool {
+	if !rotatable(dexv1Client) {
+		return false
+	}
+	if _, ok := dexv1Client.GetAnnotations()[dexv1.RotateSecretAnnotation]; ok {
+		return true
+	}
+	next := nextRotation(dexv1Client, now)
+	return next < 0
+}
+
+// nextRotation returns the time until the next scheduled rotation, zero when the
+// client has no rotation schedule and negative when the rotation is overdue.
+func nextRotation(dexv1Client dexv1.ClientObject, now time.Time) time.Duration {
+	spec, status := dexv1Client.GetClientSpec(), dexv1Client.GetClientStatus()
+	rotation := spec.Rotation
+	if !rotatable(dexv1Client) || rotation == nil || rotation.Interval.Duration <= 0 {
+		return 0
+	}
+	last := dexv1Client.GetCreationTimestamp().Time
+	if status.LastRotationTime != nil {
+		last = status.LastRotationTime.Time
+	}
+	next := last.Add(rotation.Interval.Duration).Sub(now)
+	if next == 0 {
+		next = -1
+	}
+	return next
+}
+
+// rotateSecret writes a new generated secret to the backing Secret before pushing
+// it to dex, a failed push is retried through the secret hash on the next reconcile.
+func (r *ClientReconciler) rotateSecret(ctx context.Context, dex dexapi.Interface, dexv1Client dexv1.ClientObject, wanted dexapi.CreateClientRequest) (ctrl.Result, error) {
+	spec, status := dexv1Client.GetClientSpec(), dexv1Client.GetClientStatus()
+	log := r.Log.WithValues("client", dexv1Client.GetName())
+	log.Info("Rotating client secret")
+	namespacedName, err := generatedSecretName(dexv1Client)
+	if err != nil {
+		return r.rotationFailed(ctx, dexv1Client, err)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, namespacedName, secret); err != nil {
+		return r.rotationFailed(ctx, dexv1Client, fmt.Errorf("unable to get secret %s: %w", namespacedName, err))
+	}
+	value, err := generateSecret(spec.SecretGeneration)
+	if err != nil {
+		return r.rotationFailed(ctx, dexv1Client, err)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[generatedSecretKey] = []byte(value)
+	if err := r.Update(ctx, secret); err != nil {
+		return r.rotationFailed(ctx, dexv1Client, fmt.Errorf("unable to update secret %s: %w", namespacedName, err))
+	}
+	if err := r.patchClient(ctx, dexv1Client, func() {
+		delete(dexv1Client.GetAnnotations(), dexv1.RotateSecretAnnotation)
+	}); err != nil {
+		return r.rotationFailed(ctx, dexv1Client, err)
+	}
+	now := metav1.Now()
+	status.LastRotationTime = &now
+	wanted.Secret = value
+	if res, err := r.recreateClient(ctx, dex, dexv1Client, wanted, []string{fieldSecret}); err != nil {
+		secretRotationFailures.Inc()
+		r.Recorder.Eventf(dexv1Client, "Warning", "SecretRotation", "client %s: %s", dexv1Client.GetName(), err.Error())
+		return res, err
+	}
+	secretRotations.Inc()
+	r.Recorder.Eventf(dexv1Client, "Normal", "SecretRotation", "client %s", dexv1Client.GetName())
+	return ctrl.Result{RequeueAfter: nextRotation(dexv1Client, now.Time)}, nil
+}
+
+func (r *ClientReconciler) rotationFailed(ctx context.Context, dexv1Client dexv1.ClientObject, err error) (ctrl.Result, error) {
+	status := dexv1Client.GetClientStatus()
+	r.Log.Error(err, "Client secret rotation failed", "client", dexv1Client.GetName())
+	secretRotationFailures.Inc()
+	status.Message = err.Error()
+	setClientCondition(dexv1Client, dexv1.ConditionSynced, metav1.ConditionFalse, dexv1.ReasonRotationFailed, err.Error())
+	r.Recorder.Eventf(dexv1Client, "Warning", "SecretRotation", "client %s: %s", dexv1Client.GetName(), err.Error())
+	if err := r.saveClient(ctx, dexv1Client); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, err
+}
+
+// generateSecret returns a cryptographically random secret
+func generateSecret(generation *dexv1.SecretGeneration) (string, error) {
+	length := generation.Length
+	if length == 0 {
+		length = dexv1.DefaultSecretLength
+	}
+	charset := generation.Charset
+	if charset == "" {
+		charset = dexv1.CharsetAlphanumeric
+	}
+	chars, ok := secretCharsets[charset]
+	if !ok {
+		return "", fmt.Errorf("unknown charset %q", charset)
+	}
+	max := big.NewInt(int64(len(chars)))
+	secret := make([]byte, length)
+	for i := range secret {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		secret[i] = chars[n.Int64()]
+	}
+	return string(secret), nil
+}
+
+// indexClientSecretRef is the field indexer for secretRefIndexKey
+func indexClientSecretRef(o runtime.Object) []string {
+	dexv1Client := o.(dexv1.ClientObject)
+	if dexv1Client.GetClientSpec().SecretRef == nil {
+		return nil
+	}
+	namespacedName, err := secretRefName(dexv1Client)
+	if err != nil {
+		return nil
+	}
+	return []string{namespacedName.String()}
+}
+
+// clientsForSecret maps a Secret to the Clients referencing it
+func (r *ClientReconciler) clientsForSecret(o handler.MapObject) []reconcile.Request {
+	namespacedName := k8stypes.NamespacedName{Name: o.Meta.GetName(), Namespace: o.Meta.GetNamespace()}
+	clients := &dexv1.ClientList{}
+	if err := r.List(context.Background(), clients, client.MatchingFields{secretRefIndexKey: namespacedName.String()}); err != nil {
+		r.Log.Error(err, "unable to list clients for secret", "secret", namespacedName)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clients.Items))
+	for _, item := range clients.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name, Namespace: item.Namespace},
+		})
+	}
+	return requests
+}
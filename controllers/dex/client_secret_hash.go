@@ -0,0 +1,127 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+const (
+	// SecretHashKeySecret is the Secret in the namespace of the operator holding the
+	// key of the client secret hashes
+	SecretHashKeySecret = "dex-operator-secret-hash-key"
+	// secretHashKeyKey is the key of the hash key in the Secret
+	secretHashKeyKey = "key"
+	// secretHashKeyLength is the length of generated hash keys in bytes
+	secretHashKeyLength = 32
+	// secretHashPrefix marks the algorithm of the hashes
+	secretHashPrefix = "hmac-sha256:"
+)
+
+// hashSecret returns the HMAC-SHA256 of a client secret. The key is held by the
+// operator, so the hashes in the status of Clients can not be brute-forced by
+// everyone who can read them.
+func hashSecret(key []byte, secret string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(secret))
+	return secretHashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// secretMatches returns true when hash is the hash of secret
+func secretMatches(key []byte, hash, secret string) bool {
+	return hmac.Equal([]byte(hash), []byte(hashSecret(key, secret)))
+}
+
+// LoadSecretHashKey returns the key of the client secret hashes from the
+// SecretHashKeySecret in the namespace, the Secret is created with a random key
+// when it does not exist. A lost key is not replaced while hashes made with it
+// exist, every one of them would look like a changed secret.
+func LoadSecretHashKey(ctx context.Context, c client.Client, namespace string) ([]byte, error) {
+	name := k8stypes.NamespacedName{Name: SecretHashKeySecret, Namespace: namespace}
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, name, secret)
+	if apierrors.IsNotFound(err) {
+		hashed, err := secretHashOwner(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if hashed != "" {
+			return nil, fmt.Errorf("secret %s is missing but %s has a secret hash made with it, restore the secret or clear status.secretHash of all Clients and ClusterClients", name, hashed)
+		}
+		key := make([]byte, secretHashKeyLength)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+			Data:       map[string][]byte{secretHashKeyKey: key},
+		}
+		err = c.Create(ctx, secret)
+		if err == nil {
+			return key, nil
+		}
+		// Another replica created it first
+		if apierrors.IsAlreadyExists(err) {
+			err = c.Get(ctx, name, secret)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := secret.Data[secretHashKeyKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", name, secretHashKeyKey)
+	}
+	return key, nil
+}
+
+// secretHashOwner describes a Client or ClusterClient with a secret hash in its status,
+// empty when there is none
+func secretHashOwner(ctx context.Context, c client.Client) (string, error) {
+	clients := &dexv1.ClientList{}
+	if err := c.List(ctx, clients); err != nil {
+		return "", err
+	}
+	for _, item := range clients.Items {
+		if item.Status.SecretHash != "" {
+			return "Client " + k8stypes.NamespacedName{Name: item.Name, Namespace: item.Namespace}.String(), nil
+		}
+	}
+	clusterClients := &dexv1.ClusterClientList{}
+	if err := c.List(ctx, clusterClients); err != nil {
+		return "", err
+	}
+	for _, item := range clusterClients.Items {
+		if item.Status.SecretHash != "" {
+			return "ClusterClient " + item.Name, nil
+		}
+	}
+	return "", nil
+}
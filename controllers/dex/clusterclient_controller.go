@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+// ClusterClientReconciler reconciles a ClusterClient object with the logic and
+// settings of the ClientReconciler. It relies on the client ID index registered by
+// the ClientReconciler, which must be set up with the same manager.
+type ClusterClientReconciler struct {
+	ClientReconciler
+}
+
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clusterclients,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clusterclients/status,verbs=get;update;patch
+
+// Reconcile reconciles cluster scoped oidc clients in dex
+func (r *ClusterClientReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	clusterClient := &dexv1.ClusterClient{}
+	if err := r.Get(ctx, req.NamespacedName, clusterClient); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return r.reconcileClient(ctx, r.Log.WithValues("clusterclient", req.Name), clusterClient)
+}
+
+// clusterClientsForSecret maps a Secret to the ClusterClients referencing it
+func (r *ClusterClientReconciler) clusterClientsForSecret(o handler.MapObject) []reconcile.Request {
+	namespacedName := k8stypes.NamespacedName{Name: o.Meta.GetName(), Namespace: o.Meta.GetNamespace()}
+	clusterClients := &dexv1.ClusterClientList{}
+	if err := r.List(context.Background(), clusterClients, client.MatchingFields{secretRefIndexKey: namespacedName.String()}); err != nil {
+		r.Log.Error(err, "unable to list cluster clients for secret", "secret", namespacedName)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clusterClients.Items))
+	for _, item := range clusterClients.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the mananager
+func (r *ClusterClientReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ClusterClient{}, secretRefIndexKey, indexClientSecretRef); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ClusterClient{}, dexServerIndexKey, indexDexServerRef); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.ClusterClient{}, dexServerStatusIndexKey, indexDexServerStatus); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dexv1.ClusterClient{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.clusterClientsForSecret),
+		}).
+		Watches(&source.Kind{Type: &dexv1.DexServer{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.clusterClientsForDexServer),
+		}).
+		WithEventFilter(ignoreStatusUpdates{}).
+		Complete(r)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ClusterClient", func() {
+	ctx := context.TODO()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubectl-oidc", Namespace: "dex"},
+		Data:       map[string][]byte{"clientSecret": []byte("s3cr3t-value")},
+	}
+	newClusterClient := func(ref *dexv1.SecretReference) *dexv1.ClusterClient {
+		return &dexv1.ClusterClient{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubectl"},
+			Spec:       dexv1.ClientSpec{SecretRef: ref},
+		}
+	}
+
+	It("should read the secret from the referenced namespace", func() {
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+		value, err := resolveClientSecret(ctx, c, newClusterClient(&dexv1.SecretReference{Name: "kubectl-oidc", Key: "clientSecret", Namespace: "dex"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("s3cr3t-value"))
+		_, err = resolveClientSecret(ctx, c, newClusterClient(&dexv1.SecretReference{Name: "kubectl-oidc", Key: "clientSecret"}))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should require the namespace of generated secrets", func() {
+		clusterClient := newClusterClient(nil)
+		clusterClient.Spec.SecretGeneration = &dexv1.SecretGeneration{}
+		_, err := generatedSecretName(clusterClient)
+		Expect(err).To(HaveOccurred())
+		clusterClient.Spec.SecretGeneration.Namespace = "dex"
+		Expect(generatedSecretName(clusterClient)).To(Equal(k8stypes.NamespacedName{Name: "client-secret-kubectl", Namespace: "dex"}))
+	})
+
+	It("should use the name as ID with the namespace-name strategy", func() {
+		r := &ClientReconciler{ClientIDStrategy: ClientIDStrategyNamespaceName}
+		Expect(r.resolveClientID(newClusterClient(nil))).To(Equal("kubectl"))
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+const (
+	// connectBaseDelay is the delay before connecting to dex again after a failure,
+	// doubled on every further failure
+	connectBaseDelay = 2 * time.Second
+	// connectMaxDelay is the maximum delay between attempts to connect to dex
+	connectMaxDelay = time.Minute
+)
+
+// DexConnector builds the connection to the default dex instance in the background,
+// so the operator starts before dex or its client certificate are available.
+// Clients of the default instance wait with the WaitingForDex reason until it is
+// connected.
+type DexConnector struct {
+	Log logr.Logger
+	// DexClients is the pool the connection is set as default in
+	DexClients *dexapi.Pool
+	// Connect builds the connection, it is retried with backoff until it succeeds
+	Connect func() (dexapi.Interface, error)
+}
+
+// Start implements manager.Runnable, it connects to dex and returns once connected
+// or when stop is closed
+func (c *DexConnector) Start(stop <-chan struct{}) error {
+	delay := connectBaseDelay
+	for {
+		dex, err := c.Connect()
+		if err == nil {
+			c.DexClients.SetDefault(dex)
+			c.Log.Info("Connected to dex")
+			c.negotiate(dex)
+			return nil
+		}
+		c.Log.Error(err, "unable to connect to dex, retrying", "delay", delay)
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > connectMaxDelay {
+			delay = connectMaxDelay
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica
+// connects so the prober of each replica has a connection to probe and a new leader
+// can reconcile right away
+func (c *DexConnector) NeedLeaderElection() bool {
+	return false
+}
+
+// negotiate reads the API level of dex, all capabilities are assumed until dex can
+// be reached
+func (c *DexConnector) negotiate(dex dexapi.Interface) {
+	ctx, cancel := context.WithTimeout(context.Background(), negotiationTimeout)
+	defer cancel()
+	caps, err := dex.Negotiate(ctx)
+	if err != nil {
+		c.Log.Error(err, "unable to negotiate the dex API level")
+		return
+	}
+	c.Log.Info("Negotiated the dex API level", "version", caps.Server, "api", caps.APILevel, "unsupported", caps.Unsupported())
+}
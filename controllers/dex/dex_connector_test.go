@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("DexConnector", func() {
+	It("should set the default connection once connected", func() {
+		server := dextest.NewServer()
+		defer server.Stop()
+		pool := dexapi.NewPool(nil)
+		_, err := pool.Get("")
+		Expect(errors.Is(err, dexapi.ErrNotConnected)).To(BeTrue())
+
+		connector := &DexConnector{
+			Log:        logf.Log,
+			DexClients: pool,
+			Connect: func() (dexapi.Interface, error) {
+				return server.NewClient()
+			},
+		}
+		Expect(connector.Start(make(chan struct{}))).To(Succeed())
+		dex, err := pool.Get("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dex.Capabilities().Negotiated).To(BeTrue())
+		Expect(pool.Clients()).To(HaveKey(""))
+	})
+
+	It("should stop connecting when the manager stops", func() {
+		attempts := 0
+		connector := &DexConnector{
+			Log:        logf.Log,
+			DexClients: dexapi.NewPool(nil),
+			Connect: func() (dexapi.Interface, error) {
+				attempts++
+				return nil, errors.New("open /etc/dex/tls/ca.crt: no such file or directory")
+			},
+		}
+		stop := make(chan struct{})
+		close(stop)
+		Expect(connector.Start(stop)).To(Succeed())
+		Expect(attempts).To(Equal(1))
+		Expect(connector.DexClients.Clients()).To(BeEmpty())
+	})
+
+	It("should let clients wait until connected", func() {
+		r := &ClientReconciler{DexClients: dexapi.NewPool(nil), Recorder: record.NewFakeRecorder(10)}
+		dexv1Client := &dexv1.Client{}
+		_, err := r.dexServer(dexv1Client)
+		Expect(r.notConnected(dexv1Client, err)).To(BeTrue())
+		available := dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionDexAvailable)
+		Expect(available.Reason).To(Equal(dexv1.ReasonWaitingForDex))
+
+		summarizeConditions(dexv1Client)
+		Expect(dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionReady).Reason).To(Equal(dexv1.ReasonWaitingForDex))
+		Expect(r.notConnected(dexv1Client, errors.New("connection refused"))).To(BeFalse())
+	})
+})
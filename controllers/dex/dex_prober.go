@@ -0,0 +1,205 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// dexProbeTimeout is the deadline of a single probe
+const dexProbeTimeout = 5 * time.Second
+
+var (
+	dexUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dex_up",
+			Help: "Whether the last probe of dex succeeded, the dexserver is empty for the default instance",
+		},
+		[]string{"dexserver"},
+	)
+	dexServerInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dex_server_info",
+			Help: "Version of the dex server, the value is always 1",
+		},
+		[]string{"dexserver", "version"},
+	)
+	dexAPILevel = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dex_api_level",
+			Help: "API level of the dex server, increased on changes of the gRPC API",
+		},
+		[]string{"dexserver"},
+	)
+	dexLastContact = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dex_last_contact_timestamp_seconds",
+			Help: "Time of the last successful probe of dex",
+		},
+		[]string{"dexserver"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(dexUp, dexServerInfo, dexAPILevel, dexLastContact)
+}
+
+// dexProbe is the result of the last probe of a dex server
+type dexProbe struct {
+	err         error
+	version     dexapi.ServerVersion
+	lastContact time.Time
+}
+
+// DexProber checks the connections to dex in the background with the GetVersion
+// RPC. The results let the reconcilers wait instead of failing clients while dex is
+// down and back the dex readiness check, which is only registered without webhooks.
+type DexProber struct {
+	Log logr.Logger
+	// DexClients is the pool shared with the client reconcilers
+	DexClients *dexapi.Pool
+	// Interval is the interval at which dex is probed
+	Interval time.Duration
+
+	mu     sync.RWMutex
+	probes map[string]dexProbe
+}
+
+// Start implements manager.Runnable, it probes dex until stop is closed
+func (p *DexProber) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		p.probe(context.Background())
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica probes
+// dex so its metrics and dex readiness check are current and a new leader does not
+// start with unprobed servers
+func (p *DexProber) NeedLeaderElection() bool {
+	return false
+}
+
+// probe probes all dex servers of the pool and updates the metrics
+func (p *DexProber) probe(ctx context.Context) {
+	clients := p.DexClients.Clients()
+	probes := make(map[string]dexProbe, len(clients))
+
+	p.mu.RLock()
+	previous := p.probes
+	p.mu.RUnlock()
+
+	for server, dex := range clients {
+		result := previous[server]
+		probeCtx, cancel := context.WithTimeout(ctx, dexProbeTimeout)
+		version, err := dex.GetVersion(probeCtx)
+		cancel()
+
+		result.err = err
+		if err != nil {
+			if previous[server].err == nil {
+				p.Log.Error(err, "Dex is unavailable", "dexserver", server)
+			}
+			dexUp.WithLabelValues(server).Set(0)
+		} else {
+			if _, ok := previous[server]; !ok || previous[server].err != nil {
+				p.Log.Info("Dex is available", "dexserver", server, "version", version.Server, "api", version.API)
+			}
+			if result.version.Server != version.Server {
+				dexServerInfo.DeleteLabelValues(server, result.version.Server)
+			}
+			result.version = version
+			result.lastContact = time.Now()
+			dexUp.WithLabelValues(server).Set(1)
+			dexServerInfo.WithLabelValues(server, version.Server).Set(1)
+			dexAPILevel.WithLabelValues(server).Set(float64(version.API))
+			dexLastContact.WithLabelValues(server).Set(float64(result.lastContact.Unix()))
+		}
+		probes[server] = result
+	}
+
+	// Servers removed from the pool are not reported any more
+	for server, result := range previous {
+		if _, ok := probes[server]; !ok {
+			dexUp.DeleteLabelValues(server)
+			dexServerInfo.DeleteLabelValues(server, result.version.Server)
+			dexAPILevel.DeleteLabelValues(server)
+			dexLastContact.DeleteLabelValues(server)
+		}
+	}
+
+	p.mu.Lock()
+	p.probes = probes
+	p.mu.Unlock()
+}
+
+// Unavailable returns the error of the last probe of the server, nil when it
+// succeeded or the server was not probed yet
+func (p *DexProber) Unavailable(server string) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	result, ok := p.probes[server]
+	if !ok || result.err == nil {
+		return nil
+	}
+	since := "never reached"
+	if !result.lastContact.IsZero() {
+		since = "last reached " + result.lastContact.UTC().Format(time.RFC3339)
+	}
+	return fmt.Errorf("dex %s is unavailable (%s): %w", serverName(server), since, result.err)
+}
+
+// Check is the dex readiness check of the operator, it fails until the default dex
+// instance was probed successfully and while it is unavailable
+func (p *DexProber) Check(_ *http.Request) error {
+	p.mu.RLock()
+	result, ok := p.probes[""]
+	p.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("dex was not probed yet")
+	}
+	if result.err != nil {
+		return p.Unavailable("")
+	}
+	return nil
+}
+
+// serverName returns a readable name of the dex server, the default instance has
+// an empty name
+func serverName(server string) string {
+	if server == "" {
+		return "default instance"
+	}
+	return strconv.Quote(server)
+}
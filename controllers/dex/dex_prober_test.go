@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("DexProber", func() {
+	It("should only be ready once the default instance was reached", func() {
+		p := &DexProber{}
+		Expect(p.Check(nil)).NotTo(Succeed())
+		Expect(p.Unavailable("")).To(Succeed())
+
+		p.probes = map[string]dexProbe{"": {err: errors.New("connection refused")}}
+		Expect(p.Check(nil)).To(MatchError(ContainSubstring("never reached")))
+		Expect(p.Unavailable("")).To(MatchError(ContainSubstring("never reached")))
+		Expect(p.Unavailable("partners")).To(Succeed())
+
+		p.probes = map[string]dexProbe{"": {lastContact: time.Now()}}
+		Expect(p.Check(nil)).To(Succeed())
+		Expect(p.Unavailable("")).To(Succeed())
+	})
+
+	It("should let clients wait for unavailable servers", func() {
+		recorder := record.NewFakeRecorder(10)
+		r := &ClientReconciler{Recorder: recorder, Prober: &DexProber{probes: map[string]dexProbe{
+			"partners": {err: errors.New("connection refused"), lastContact: time.Now()},
+		}}}
+		dexv1Client := &dexv1.Client{}
+		Expect(r.dexAvailable(dexv1Client)).To(Succeed())
+		Expect(dexv1.IsConditionTrue(dexv1Client.Status.Conditions, dexv1.ConditionDexAvailable)).To(BeTrue())
+
+		dexv1Client.Status.DexServer = "partners"
+		Expect(r.dexAvailable(dexv1Client)).To(MatchError(ContainSubstring("last reached")))
+		available := dexv1.FindCondition(dexv1Client.Status.Conditions, dexv1.ConditionDexAvailable)
+		Expect(available.Reason).To(Equal(dexv1.ReasonDexUnavailable))
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning DexUnavailable")))
+
+		// Only the change of the condition is recorded
+		Expect(r.dexAvailable(dexv1Client)).NotTo(Succeed())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
@@ -0,0 +1,309 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+// tlsSecretRefIndexKey indexes DexServers by the namespace/name of their TLS Secret
+const tlsSecretRefIndexKey = "spec.tlsSecretRef"
+
+const (
+	// negotiationTimeout is the deadline to read the API level of a DexServer
+	negotiationTimeout = 10 * time.Second
+	// negotiationRetryDelay is the delay before reading the API level of an
+	// unavailable DexServer again
+	negotiationRetryDelay = time.Minute
+)
+
+// DexServerReconciler keeps a connection in the pool for every DexServer
+type DexServerReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// DexClients is the pool shared with the client reconcilers
+	DexClients *dexapi.Pool
+	// DexOptions are the timeouts, retries and interceptors of the connections,
+	// the endpoint and certificates are taken from the DexServer
+	DexOptions dexapi.Options
+}
+
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=dexservers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=dexservers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dex.betssongroup.com,resources=clients;clusterclients,verbs=get;list;watch
+
+// Reconcile builds the connection to a DexServer, it is rebuilt when the endpoint or
+// the TLS Secret change. A deleted DexServer keeps its connection until no client
+// references it, so the clients can still be deleted from dex.
+func (r *DexServerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("dexserver", req.Name)
+
+	server := &dexv1.DexServer{}
+	if err := r.Get(ctx, req.NamespacedName, server); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Removing connection of deleted dex server")
+			r.DexClients.Remove(req.Name)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Delete
+	dexServerFinalizer := "dexserver.dex.finalizers.betssongroup.com"
+	if server.DeletionTimestamp.IsZero() {
+		if !containsString(server.Finalizers, dexServerFinalizer) {
+			log.Info("Adding finalizer")
+			patch := client.MergeFrom(server.DeepCopy())
+			server.Finalizers = append(server.Finalizers, dexServerFinalizer)
+			if err := r.Patch(ctx, server, patch); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	} else {
+		if containsString(server.Finalizers, dexServerFinalizer) {
+			return ctrl.Result{}, r.finalizeDexServer(ctx, log, server, dexServerFinalizer)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// A missing or broken Secret keeps the existing connection
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, tlsSecretName(server), secret); err != nil {
+		reason := dexv1.ReasonSecretInvalid
+		if apierrors.IsNotFound(err) {
+			reason = dexv1.ReasonSecretNotFound
+		}
+		return r.connectionFailed(ctx, server, reason, err)
+	}
+	hash := connectionHash(server, secret)
+	if r.DexClients.Hash(server.Name) != hash {
+		opts := r.DexOptions
+		opts.HostAndPort = server.Spec.GRPC
+		dex, err := dexapi.NewClientFromPEM(&opts, secret.Data[dexv1.DexServerCAKey],
+			secret.Data[dexv1.DexServerCertKey], secret.Data[dexv1.DexServerKeyKey])
+		if err != nil {
+			return r.connectionFailed(ctx, server, dexv1.ReasonConnectionFailed, err)
+		}
+		r.DexClients.Set(server.Name, dex, hash)
+		log.Info("Connection to dex server built", "grpc", server.Spec.GRPC)
+		r.Recorder.Eventf(server, "Normal", "DexServerConnection", "dex server %s: connected to %s", server.Name, server.Spec.GRPC)
+	}
+	server.Status.ObservedGeneration = server.Generation
+
+	// Negotiate the API level, the features of clients follow the capabilities. The
+	// server is only ready once it answered.
+	result := ctrl.Result{}
+	dex, err := r.DexClients.Get(server.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	negotiationCtx, cancel := context.WithTimeout(ctx, negotiationTimeout)
+	caps, err := dex.Negotiate(negotiationCtx)
+	cancel()
+	if err != nil {
+		log.Error(err, "unable to negotiate the API level of the dex server")
+		setDexServerCondition(server, metav1.ConditionFalse, dexv1.ReasonNegotiationFailed, err.Error())
+		result.RequeueAfter = negotiationRetryDelay
+	} else {
+		setDexServerCapabilities(server, caps)
+		setDexServerCondition(server, metav1.ConditionTrue, dexv1.ReasonConfigured, "")
+	}
+	return result, r.Status().Update(ctx, server)
+}
+
+// finalizeDexServer releases the finalizer once no Client or ClusterClient references
+// the DexServer, the client watches reconcile it again when they are deleted
+func (r *DexServerReconciler) finalizeDexServer(ctx context.Context, log logr.Logger, server *dexv1.DexServer, finalizer string) error {
+	remaining, err := r.referencingClients(ctx, server.Name)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		message := fmt.Sprintf("%d clients still reference the dex server", remaining)
+		log.Info("Waiting for clients before deleting the dex server", "clients", remaining)
+		if ready := dexv1.FindCondition(server.Status.Conditions, dexv1.ConditionReady); ready == nil || ready.Reason != dexv1.ReasonInUse {
+			r.Recorder.Eventf(server, "Warning", "DexServerInUse", "dex server %s: %s", server.Name, message)
+		}
+		setDexServerCondition(server, metav1.ConditionFalse, dexv1.ReasonInUse, message)
+		return r.Status().Update(ctx, server)
+	}
+	log.Info("Removing connection of deleted dex server")
+	r.DexClients.Remove(server.Name)
+	patch := client.MergeFrom(server.DeepCopy())
+	server.Finalizers = removeString(server.Finalizers, finalizer)
+	return r.Patch(ctx, server, patch)
+}
+
+// referencingClients counts the Clients and ClusterClients referencing the DexServer
+// or created in it, a client whose dexServerRef was changed after its creation still
+// lives in the server of its status
+func (r *DexServerReconciler) referencingClients(ctx context.Context, name string) (int, error) {
+	referencing := map[k8stypes.UID]bool{}
+	for _, key := range []string{dexServerIndexKey, dexServerStatusIndexKey} {
+		clients := &dexv1.ClientList{}
+		if err := r.List(ctx, clients, client.MatchingFields{key: name}); err != nil {
+			return 0, err
+		}
+		for _, item := range clients.Items {
+			referencing[item.UID] = true
+		}
+		clusterClients := &dexv1.ClusterClientList{}
+		if err := r.List(ctx, clusterClients, client.MatchingFields{key: name}); err != nil {
+			return 0, err
+		}
+		for _, item := range clusterClients.Items {
+			referencing[item.UID] = true
+		}
+	}
+	return len(referencing), nil
+}
+
+// dexServerForClient maps a Client or ClusterClient to the DexServer it references
+// and the one it was created in
+func dexServerForClient(o handler.MapObject) []reconcile.Request {
+	dexv1Client := o.Object.(dexv1.ClientObject)
+	var requests []reconcile.Request
+	if name := dexServerRefName(dexv1Client); name != "" {
+		requests = append(requests, reconcile.Request{NamespacedName: k8stypes.NamespacedName{Name: name}})
+	}
+	if name := dexv1Client.GetClientStatus().DexServer; name != "" && name != dexServerRefName(dexv1Client) {
+		requests = append(requests, reconcile.Request{NamespacedName: k8stypes.NamespacedName{Name: name}})
+	}
+	return requests
+}
+
+// setDexServerCapabilities records the negotiated version and capabilities in the
+// status of the DexServer
+func setDexServerCapabilities(server *dexv1.DexServer, caps dexapi.Capabilities) {
+	apiLevel := caps.APILevel
+	server.Status.Version = caps.Server
+	server.Status.APILevel = &apiLevel
+	server.Status.UnsupportedCapabilities = caps.Unsupported()
+}
+
+// connectionFailed reports a DexServer whose connection could not be built
+func (r *DexServerReconciler) connectionFailed(ctx context.Context, server *dexv1.DexServer, reason string, err error) (ctrl.Result, error) {
+	r.Log.Error(err, "Dex server connection failed", "dexserver", server.Name)
+	setDexServerCondition(server, metav1.ConditionFalse, reason, err.Error())
+	r.Recorder.Eventf(server, "Warning", "DexServerConnection", "dex server %s: %s", server.Name, err.Error())
+	if err := r.Status().Update(ctx, server); err != nil {
+		return ctrl.Result{}, err
+	}
+	// A missing Secret is picked up by the secret watch once it is created
+	if reason == dexv1.ReasonSecretNotFound {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// setDexServerCondition sets the Ready condition of the DexServer
+func setDexServerCondition(server *dexv1.DexServer, status metav1.ConditionStatus, reason, message string) {
+	dexv1.SetCondition(&server.Status.Conditions, dexv1.Condition{
+		Type:               dexv1.ConditionReady,
+		Status:             status,
+		ObservedGeneration: server.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// tlsSecretName returns the namespaced name of the TLS Secret of the DexServer
+func tlsSecretName(server *dexv1.DexServer) k8stypes.NamespacedName {
+	return k8stypes.NamespacedName{Name: server.Spec.TLSSecretRef.Name, Namespace: server.Spec.TLSSecretRef.Namespace}
+}
+
+// connectionHash returns the hex encoded SHA-256 of the endpoint and the TLS material
+// of a DexServer
+func connectionHash(server *dexv1.DexServer, secret *corev1.Secret) string {
+	sum := sha256.New()
+	for _, value := range [][]byte{
+		[]byte(server.Spec.GRPC),
+		secret.Data[dexv1.DexServerCAKey],
+		secret.Data[dexv1.DexServerCertKey],
+		secret.Data[dexv1.DexServerKeyKey],
+	} {
+		sum.Write(value)
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// indexTLSSecretRef is the field indexer for tlsSecretRefIndexKey
+func indexTLSSecretRef(o runtime.Object) []string {
+	return []string{tlsSecretName(o.(*dexv1.DexServer)).String()}
+}
+
+// dexServersForSecret maps a Secret to the DexServers using it
+func (r *DexServerReconciler) dexServersForSecret(o handler.MapObject) []reconcile.Request {
+	namespacedName := k8stypes.NamespacedName{Name: o.Meta.GetName(), Namespace: o.Meta.GetNamespace()}
+	servers := &dexv1.DexServerList{}
+	if err := r.List(context.Background(), servers, client.MatchingFields{tlsSecretRefIndexKey: namespacedName.String()}); err != nil {
+		r.Log.Error(err, "unable to list dex servers for secret", "secret", namespacedName)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(servers.Items))
+	for _, item := range servers.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{Name: item.Name},
+		})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the mananager
+func (r *DexServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&dexv1.DexServer{}, tlsSecretRefIndexKey, indexTLSSecretRef); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dexv1.DexServer{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.dexServersForSecret),
+		}).
+		Watches(&source.Kind{Type: &dexv1.Client{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(dexServerForClient),
+		}).
+		Watches(&source.Kind{Type: &dexv1.ClusterClient{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(dexServerForClient),
+		}).
+		WithEventFilter(ignoreStatusUpdates{}).
+		Complete(r)
+}
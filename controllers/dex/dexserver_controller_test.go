@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("DexServer", func() {
+	newClient := func(server string) *dexv1.Client {
+		return &dexv1.Client{
+			ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "team-a"},
+			Spec:       dexv1.ClientSpec{DexServerRef: &dexv1.DexServerReference{Name: server}},
+		}
+	}
+
+	It("should use the referenced server and keep it once the client is created", func() {
+		partners := &dexapi.APIClient{}
+		r := &ClientReconciler{DexClients: dexapi.NewPool(&dexapi.APIClient{})}
+		dexv1Client := newClient("partners")
+		_, err := r.dexServer(dexv1Client)
+		Expect(errors.Is(err, dexapi.ErrUnknownServer)).To(BeTrue())
+
+		r.DexClients.Set("partners", partners, "hash")
+		Expect(r.dexServer(dexv1Client)).To(BeIdenticalTo(partners))
+		Expect(dexv1Client.Status.DexServer).To(Equal("partners"))
+
+		dexv1Client.Status.ClientID = "grafana"
+		dexv1Client.Spec.DexServerRef = nil
+		_, err = r.dexServer(dexv1Client)
+		Expect(errors.Is(err, errDexServerChanged)).To(BeTrue())
+	})
+
+	It("should scope client IDs to their server", func() {
+		Expect(clientIDIndexValue("", "grafana")).To(Equal("grafana"))
+		Expect(clientIDIndexValue("partners", "grafana")).To(Equal("partners/grafana"))
+	})
+
+	It("should rebuild connections when the TLS material changes", func() {
+		server := &dexv1.DexServer{Spec: dexv1.DexServerSpec{GRPC: "dex:35000"}}
+		secret := &corev1.Secret{Data: map[string][]byte{dexv1.DexServerCertKey: []byte("cert")}}
+		hash := connectionHash(server, secret)
+		Expect(connectionHash(server, secret)).To(Equal(hash))
+		secret.Data[dexv1.DexServerCertKey] = []byte("renewed")
+		Expect(connectionHash(server, secret)).NotTo(Equal(hash))
+	})
+
+	It("should only be ready once the API level was negotiated", func() {
+		dexServer := dextest.NewServer()
+		defer dexServer.Stop()
+		dex, err := dexServer.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+		server := &dexv1.DexServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "partners"},
+			Spec: dexv1.DexServerSpec{
+				GRPC:         "dex:35000",
+				TLSSecretRef: dexv1.TLSSecretReference{Name: "dex-partners-tls", Namespace: "dex"},
+			},
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "dex-partners-tls", Namespace: "dex"}}
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, server, secret)
+		r := &DexServerReconciler{Client: c, Log: logf.Log, Recorder: record.NewFakeRecorder(10), DexClients: dexapi.NewPool(nil)}
+		r.DexClients.Set("partners", dex, connectionHash(server, secret))
+		key := k8stypes.NamespacedName{Name: "partners"}
+		ready := func() *dexv1.Condition {
+			Expect(c.Get(context.Background(), key, server)).To(Succeed())
+			return dexv1.FindCondition(server.Status.Conditions, dexv1.ConditionReady)
+		}
+
+		dexServer.Inject(dextest.Fault{Method: "GetVersion", Code: codes.Unavailable, Times: 1})
+		result, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(negotiationRetryDelay))
+		Expect(ready().Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready().Reason).To(Equal(dexv1.ReasonNegotiationFailed))
+
+		_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ready().Status).To(Equal(metav1.ConditionTrue))
+		Expect(server.Status.APILevel).NotTo(BeNil())
+	})
+
+	It("should keep deleted servers until no client references them", func() {
+		now := metav1.Now()
+		server := &dexv1.DexServer{ObjectMeta: metav1.ObjectMeta{
+			Name:              "partners",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"dexserver.dex.finalizers.betssongroup.com"},
+		}}
+		dexv1Client := newClient("partners")
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, server, dexv1Client)
+		r := &DexServerReconciler{Client: c, Log: logf.Log, Recorder: record.NewFakeRecorder(10), DexClients: dexapi.NewPool(nil)}
+		r.DexClients.Set("partners", &dexapi.APIClient{}, "hash")
+		key := k8stypes.NamespacedName{Name: "partners"}
+
+		Expect(r.finalizeDexServer(context.Background(), logf.Log, server, server.Finalizers[0])).To(Succeed())
+		Expect(c.Get(context.Background(), key, server)).To(Succeed())
+		Expect(server.Finalizers).To(HaveLen(1))
+		Expect(dexv1.FindCondition(server.Status.Conditions, dexv1.ConditionReady).Reason).To(Equal(dexv1.ReasonInUse))
+		Expect(r.DexClients.Get("partners")).NotTo(BeNil())
+
+		Expect(c.Delete(context.Background(), dexv1Client)).To(Succeed())
+		Expect(r.finalizeDexServer(context.Background(), logf.Log, server, server.Finalizers[0])).To(Succeed())
+		Expect(c.Get(context.Background(), key, server)).To(Succeed())
+		Expect(server.Finalizers).To(BeEmpty())
+		_, err := r.DexClients.Get("partners")
+		Expect(errors.Is(err, dexapi.ErrUnknownServer)).To(BeTrue())
+	})
+
+	It("should count clients created in the server whose ref was changed", func() {
+		dexv1Client := newClient("other")
+		dexv1Client.Status.DexServer = "partners"
+		Expect(indexDexServerRef(dexv1Client)).To(ConsistOf("other"))
+		Expect(indexDexServerStatus(dexv1Client)).To(ConsistOf("partners"))
+		Expect(dexServerForClient(handler.MapObject{Object: dexv1Client})).To(ConsistOf(
+			reconcile.Request{NamespacedName: k8stypes.NamespacedName{Name: "other"}},
+			reconcile.Request{NamespacedName: k8stypes.NamespacedName{Name: "partners"}},
+		))
+
+		dexv1Client.UID = "grafana"
+		c := fake.NewFakeClientWithScheme(scheme.Scheme, dexv1Client)
+		r := &DexServerReconciler{Client: c, Log: logf.Log}
+		Expect(r.referencingClients(context.Background(), "partners")).To(Equal(1))
+	})
+})
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+)
+
+// ignoreStatusUpdates filters out updates of dex resources which only change their
+// status, so writing the status does not trigger another reconcile. Changes to the
+// spec, annotations, finalizers and deletion are passed, as are all events of other
+// resources like Secrets, whose generation does not change with their data.
+type ignoreStatusUpdates struct {
+	predicate.Funcs
+}
+
+// Update implements predicate.Predicate
+func (ignoreStatusUpdates) Update(e event.UpdateEvent) bool {
+	switch e.ObjectNew.(type) {
+	case *dexv1.Client, *dexv1.ClusterClient, *dexv1.ALBAuth:
+	default:
+		return true
+	}
+	if e.MetaOld == nil || e.MetaNew == nil {
+		return true
+	}
+	return e.MetaNew.GetGeneration() != e.MetaOld.GetGeneration() ||
+		!reflect.DeepEqual(e.MetaNew.GetAnnotations(), e.MetaOld.GetAnnotations()) ||
+		!reflect.DeepEqual(e.MetaNew.GetFinalizers(), e.MetaOld.GetFinalizers()) ||
+		!e.MetaNew.GetDeletionTimestamp().Equal(e.MetaOld.GetDeletionTimestamp())
+}
+
+// ignoreALBAuthStatusUpdates filters out updates of ALBAuths which only change their
+// status. Status updates of Clients are passed, an ALBAuth waits for its Client to
+// become active.
+type ignoreALBAuthStatusUpdates struct {
+	predicate.Funcs
+}
+
+// Update implements predicate.Predicate
+func (ignoreALBAuthStatusUpdates) Update(e event.UpdateEvent) bool {
+	if _, ok := e.ObjectNew.(*dexv1.ALBAuth); !ok {
+		return true
+	}
+	return ignoreStatusUpdates{}.Update(e)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("ignoreStatusUpdates", func() {
+	updateEvent := func(old, new runtime.Object) event.UpdateEvent {
+		oldMeta, _ := meta.Accessor(old)
+		newMeta, _ := meta.Accessor(new)
+		return event.UpdateEvent{MetaOld: oldMeta, ObjectOld: old, MetaNew: newMeta, ObjectNew: new}
+	}
+
+	It("should ignore status only updates of clients", func() {
+		old := &dexv1.Client{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Generation: 1}}
+		new := old.DeepCopy()
+		new.Status.State = dexv1.PhaseActive
+		Expect(ignoreStatusUpdates{}.Update(updateEvent(old, new))).To(BeFalse())
+		new.Generation = 2
+		Expect(ignoreStatusUpdates{}.Update(updateEvent(old, new))).To(BeTrue())
+	})
+
+	It("should pass annotation changes of clients", func() {
+		old := &dexv1.Client{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Generation: 1}}
+		new := old.DeepCopy()
+		new.Annotations = map[string]string{dexv1.RotateSecretAnnotation: "now"}
+		Expect(ignoreStatusUpdates{}.Update(updateEvent(old, new))).To(BeTrue())
+	})
+
+	It("should pass updates of secrets", func() {
+		old := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "oidc"}}
+		new := old.DeepCopy()
+		new.Data = map[string][]byte{"clientSecret": []byte("s3cr3t")}
+		Expect(ignoreStatusUpdates{}.Update(updateEvent(old, new))).To(BeTrue())
+	})
+})
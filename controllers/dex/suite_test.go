@@ -37,6 +37,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,6 +49,9 @@ var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
 
+// fakeDex is the in-memory dex the controllers of SetupTest talk to
+var fakeDex *dextest.Server
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -80,11 +85,16 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).ToNot(HaveOccurred())
 	Expect(k8sClient).ToNot(BeNil())
 
+	fakeDex = dextest.NewServer()
+
 	close(done)
 }, 60)
 
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
+	if fakeDex != nil {
+		fakeDex.Stop()
+	}
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
@@ -92,8 +102,9 @@ var _ = AfterSuite(func() {
 // SetupTest will set up a testing environment.
 // This includes:
 // * creating a Namespace to be used during the test
-// * starting the 'MyKindReconciler'
-// * stopping the 'MyKindReconciler" after the test ends
+// * emptying fakeDex
+// * starting the 'ClientReconciler' against fakeDex and the 'ALBAuthReconciler'
+// * stopping the reconcilers after the test ends
 // Call this function at the start of each of your tests.
 func SetupTest(ctx context.Context) *core.Namespace {
 	var stopCh chan struct{}
@@ -108,17 +119,39 @@ func SetupTest(ctx context.Context) *core.Namespace {
 		err := k8sClient.Create(ctx, ns)
 		Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
 
-		mgr, err := ctrl.NewManager(cfg, ctrl.Options{})
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{MetricsBindAddress: "0"})
 		Expect(err).NotTo(HaveOccurred(), "failed to create manager")
 
+		fakeDex.Reset()
+		dex, err := fakeDex.NewClient()
+		Expect(err).NotTo(HaveOccurred(), "failed to connect to fake dex")
+
 		controller := &ClientReconciler{
-			Client:   mgr.GetClient(),
-			Log:      logf.Log,
-			Recorder: mgr.GetEventRecorderFor("dex-operator"),
+			Client:            mgr.GetClient(),
+			Log:               logf.Log,
+			Scheme:            mgr.GetScheme(),
+			DexClients:        dexapi.NewPool(dex),
+			Recorder:          mgr.GetEventRecorderFor("dex-operator"),
+			RetryBaseDelay:    100 * time.Millisecond,
+			RetryMaxDelay:     time.Second,
+			RetainedNamespace: ns.Name,
+			APIReader:         mgr.GetAPIReader(),
+			ClientIDStrategy:  ClientIDStrategyNamespaceName,
+			SecretHashKey:     []byte("test"),
 		}
 		err = controller.SetupWithManager(mgr)
 		Expect(err).NotTo(HaveOccurred(), "failed to setup controller")
 
+		albAuthController := &ALBAuthReconciler{
+			Client:        mgr.GetClient(),
+			Log:           logf.Log,
+			Scheme:        mgr.GetScheme(),
+			Recorder:      mgr.GetEventRecorderFor("dex-operator"),
+			SecretHashKey: []byte("test"),
+		}
+		err = albAuthController.SetupWithManager(mgr)
+		Expect(err).NotTo(HaveOccurred(), "failed to setup albauth controller")
+
 		go func() {
 			err := mgr.Start(stopCh)
 			Expect(err).NotTo(HaveOccurred(), "failed to start manager")
@@ -10,6 +10,7 @@ require (
 	github.com/onsi/gomega v1.8.1
 	github.com/pkg/errors v0.8.1
 	github.com/prometheus/client_golang v1.0.0
+	golang.org/x/net v0.0.0-20191004110552-13f9640d40b9
 	google.golang.org/grpc v1.29.0
 	google.golang.org/protobuf v1.22.0
 	k8s.io/api v0.17.2
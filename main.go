@@ -17,15 +17,28 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	dexv1 "github.com/BetssonGroup/dex-operator/apis/dex/v1"
 	dexcontroller "github.com/BetssonGroup/dex-operator/controllers/dex"
@@ -51,7 +64,25 @@ func main() {
 	var dexClientCA string
 	var dexClientCert string
 	var dexClientKey string
+	var dexTimeout time.Duration
+	var dexRetries int
+	var dexRetryBackoff time.Duration
+	var dexKeepaliveTime time.Duration
+	var dexKeepaliveTimeout time.Duration
+	var dexTraceAddr string
 	var healthAddr string
+	var driftInterval time.Duration
+	var retryBaseDelay time.Duration
+	var retryMaxDelay time.Duration
+	var finalizerTimeout time.Duration
+	var deletionPolicy string
+	var probeInterval time.Duration
+	var certCheckInterval time.Duration
+	var certExpiryWarning time.Duration
+	var clientIDStrategy string
+	var secretHashKeyNamespace string
+	var alwaysAdoptionNamespaces string
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
@@ -60,11 +91,54 @@ func main() {
 	flag.StringVar(&dexClientCA, "dex-grpc-ca", "/etc/dex/tls/ca.crt", "Path to the Dex GRPC CA")
 	flag.StringVar(&dexClientCert, "dex-grpc-cert", "/etc/dex/tls/tls.crt", "Path to the Dex GRPC client certificate")
 	flag.StringVar(&dexClientKey, "dex-grpc-key", "/etc/dex/tls/tls.key", "Path to the Dex GRPC client key")
+	flag.DurationVar(&dexTimeout, "dex-grpc-timeout", 10*time.Second, "Deadline of a single Dex GRPC call, 0 disables it")
+	flag.IntVar(&dexRetries, "dex-grpc-retries", 3,
+		"Number of retries of idempotent Dex GRPC calls failing with a transient error")
+	flag.DurationVar(&dexRetryBackoff, "dex-grpc-retry-backoff", 200*time.Millisecond,
+		"Delay before retrying a Dex GRPC call, doubled on every further retry")
+	flag.DurationVar(&dexKeepaliveTime, "dex-grpc-keepalive-time", 5*time.Minute,
+		"Interval of keepalive pings to Dex while calls are in flight, 0 disables them")
+	flag.DurationVar(&dexKeepaliveTimeout, "dex-grpc-keepalive-timeout", 20*time.Second,
+		"Time to wait for the answer to a keepalive ping before closing the connection")
+	flag.StringVar(&dexTraceAddr, "dex-grpc-trace-addr", "",
+		"The address traces of the Dex GRPC calls are served at, on /debug/requests. Disabled when empty.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the defaulting and validating admission webhooks, needs a serving certificate")
 	flag.StringVar(&healthAddr, "health-addr", ":9440", "The address the health endpoint binds to.")
+	flag.DurationVar(&driftInterval, "drift-interval", 10*time.Minute,
+		"Interval at which active clients are compared with Dex and corrected, 0 disables drift detection")
+	flag.DurationVar(&retryBaseDelay, "retry-base-delay", 5*time.Second,
+		"Delay before retrying a client which failed with a transient error, doubled on every further failure")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", 10*time.Minute, "Maximum delay between retries of a failed client")
+	flag.DurationVar(&finalizerTimeout, "finalizer-timeout", 0,
+		"Time after which a deleted client is released although it could not be deleted from Dex, 0 waits forever")
+	flag.DurationVar(&probeInterval, "dex-probe-interval", 30*time.Second,
+		"Interval at which the availability of Dex is probed")
+	flag.DurationVar(&certCheckInterval, "cert-check-interval", 5*time.Minute,
+		"Interval at which the expiry of the Dex GRPC client certificates is checked")
+	flag.DurationVar(&certExpiryWarning, "cert-expiry-warning", 7*24*time.Hour,
+		"Time before the expiry of a Dex GRPC client certificate from which on warnings are recorded")
+	flag.StringVar(&deletionPolicy, "default-deletion-policy", dexv1.DeletionPolicyDelete,
+		"Deletion policy of Clients without spec.deletionPolicy, one of "+strings.Join(dexcontroller.DeletionPolicies, ", "))
+	flag.StringVar(&clientIDStrategy, "client-id-strategy", dexcontroller.ClientIDStrategyName,
+		"Dex client ID of Clients without spec.clientID, one of "+strings.Join(dexcontroller.ClientIDStrategies, ", "))
+	flag.StringVar(&secretHashKeyNamespace, "secret-hash-key-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace of the Secret "+dexcontroller.SecretHashKeySecret+" holding the key of the client secret hashes, created when missing and no hashes exist")
+	flag.StringVar(&alwaysAdoptionNamespaces, "always-adoption-namespaces", "",
+		"Comma separated namespaces whose Clients may use adoptionPolicy "+dexv1.AdoptionPolicyAlways+", ClusterClients always may")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	if !dexcontroller.IsClientIDStrategy(clientIDStrategy) {
+		setupLog.Error(fmt.Errorf("unknown client ID strategy %q", clientIDStrategy), "invalid flags")
+		os.Exit(1)
+	}
+	if !dexcontroller.IsDeletionPolicy(deletionPolicy) {
+		setupLog.Error(fmt.Errorf("unknown deletion policy %q", deletionPolicy), "invalid flags")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -79,37 +153,168 @@ func main() {
 	}
 
 	// Setup a dex client
+	dexInterceptors := []grpc.UnaryClientInterceptor{
+		dexapi.LoggingInterceptor(ctrl.Log.WithName("dex")),
+		dexapi.MetricsInterceptor(metrics.Registry),
+	}
+	if dexTraceAddr != "" {
+		dexInterceptors = append(dexInterceptors, dexapi.TracingInterceptor())
+		if err = mgr.Add(serveTraces(dexTraceAddr)); err != nil {
+			setupLog.Error(err, "unable to serve traces")
+			os.Exit(1)
+		}
+	}
 	dexOptions := &dexapi.Options{
-		HostAndPort: dexGrpc,
-		ClientCA:    dexClientCA,
-		ClientCrt:   dexClientCert,
-		ClientKey:   dexClientKey,
+		HostAndPort:      dexGrpc,
+		ClientCA:         dexClientCA,
+		ClientCrt:        dexClientCert,
+		ClientKey:        dexClientKey,
+		Timeout:          dexTimeout,
+		Retries:          dexRetries,
+		RetryBackoff:     dexRetryBackoff,
+		KeepaliveTime:    dexKeepaliveTime,
+		KeepaliveTimeout: dexKeepaliveTimeout,
+		Interceptors:     dexInterceptors,
 	}
-	dexClient, err := dexapi.NewClient(dexOptions)
-	if err != nil {
-		setupLog.Error(err, "unable to setup Dex grcp client")
+	// Connect in the background, clients wait until the connection is built and the
+	// flag configured client is the default, DexServers add more
+	dexClients := dexapi.NewPool(nil)
+	if err = mgr.Add(&dexcontroller.DexConnector{
+		Log:        ctrl.Log.WithName("controllers").WithName("DexConnector"),
+		DexClients: dexClients,
+		Connect: func() (dexapi.Interface, error) {
+			dexClient, err := dexapi.NewClient(dexOptions)
+			if err != nil {
+				return nil, err
+			}
+			return dexClient, nil
+		},
+	}); err != nil {
+		setupLog.Error(err, "unable to add dex connector")
 		os.Exit(1)
 	}
-	if err = (&dexcontroller.ClientReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("Client"),
-		Scheme:    mgr.GetScheme(),
-		DexClient: dexClient,
-		Recorder:  mgr.GetEventRecorderFor("dex-operator"),
+	if err = (&dexcontroller.DexServerReconciler{
+		Client:     mgr.GetClient(),
+		Log:        ctrl.Log.WithName("controllers").WithName("DexServer"),
+		Scheme:     mgr.GetScheme(),
+		Recorder:   mgr.GetEventRecorderFor("dex-operator"),
+		DexClients: dexClients,
+		DexOptions: *dexOptions,
 	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DexServer")
+		os.Exit(1)
+	}
+	// Probe dex in the background, clients wait for unavailable dex servers
+	dexProber := &dexcontroller.DexProber{
+		Log:        ctrl.Log.WithName("controllers").WithName("DexProber"),
+		DexClients: dexClients,
+		Interval:   probeInterval,
+	}
+	if err = mgr.Add(dexProber); err != nil {
+		setupLog.Error(err, "unable to add dex prober")
+		os.Exit(1)
+	}
+	// Report the expiry of the client certificates, events about the default
+	// instance are recorded for the pod of the operator
+	certificateMonitor := &dexcontroller.CertificateMonitor{
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("CertificateMonitor"),
+		Recorder:      mgr.GetEventRecorderFor("dex-operator"),
+		DexClients:    dexClients,
+		Interval:      certCheckInterval,
+		WarningPeriod: certExpiryWarning,
+	}
+	if podName, podNamespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE"); podName != "" && podNamespace != "" {
+		certificateMonitor.Pod = &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       podName,
+			Namespace:  podNamespace,
+			UID:        k8stypes.UID(os.Getenv("POD_UID")),
+		}
+	}
+	if err = mgr.Add(certificateMonitor); err != nil {
+		setupLog.Error(err, "unable to add certificate monitor")
+		os.Exit(1)
+	}
+	// Claims and retained clients are recorded in the namespace of the operator
+	operatorNamespace := os.Getenv("POD_NAMESPACE")
+	if operatorNamespace == "" {
+		setupLog.Error(fmt.Errorf("POD_NAMESPACE must be set"), "invalid environment")
+		os.Exit(1)
+	}
+	// The key is read before the manager starts, the cached client is not ready yet
+	if secretHashKeyNamespace == "" {
+		setupLog.Error(fmt.Errorf("POD_NAMESPACE or --secret-hash-key-namespace must be set"), "invalid flags")
+		os.Exit(1)
+	}
+	keyClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+	secretHashKey, err := dexcontroller.LoadSecretHashKey(context.Background(), keyClient, secretHashKeyNamespace)
+	if err != nil {
+		setupLog.Error(err, "unable to load the secret hash key", "namespace", secretHashKeyNamespace)
+		os.Exit(1)
+	}
+	clientReconciler := dexcontroller.ClientReconciler{
+		Client:            mgr.GetClient(),
+		Log:               ctrl.Log.WithName("controllers").WithName("Client"),
+		Scheme:            mgr.GetScheme(),
+		DexClients:        dexClients,
+		Prober:            dexProber,
+		Recorder:          mgr.GetEventRecorderFor("dex-operator"),
+		DriftInterval:     driftInterval,
+		RetryBaseDelay:    retryBaseDelay,
+		RetryMaxDelay:     retryMaxDelay,
+		FinalizerTimeout:  finalizerTimeout,
+		DeletionPolicy:    deletionPolicy,
+		RetainedNamespace: operatorNamespace,
+		APIReader:         mgr.GetAPIReader(),
+		ClientIDStrategy:  clientIDStrategy,
+		SecretHashKey:     secretHashKey,
+	}
+	if alwaysAdoptionNamespaces != "" {
+		clientReconciler.AlwaysAdoptionNamespaces = strings.Split(alwaysAdoptionNamespaces, ",")
+	}
+	if err = (&clientReconciler).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Client")
 		os.Exit(1)
 	}
+	// ClusterClients share the settings of Clients
+	clusterClientReconciler := &dexcontroller.ClusterClientReconciler{ClientReconciler: clientReconciler}
+	clusterClientReconciler.Log = ctrl.Log.WithName("controllers").WithName("ClusterClient")
+	if err = clusterClientReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterClient")
+		os.Exit(1)
+	}
 	if err = (&dexcontroller.ALBAuthReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ALBAuth"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("ALBAuth"),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("dex-operator"),
+		SecretHashKey: secretHashKey,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ALBAuth")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&dexv1.Client{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Client")
+			os.Exit(1)
+		}
+		if err = (&dexv1.ClusterClient{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterClient")
+			os.Exit(1)
+		}
+		if err = (&dexv1.ALBAuth{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ALBAuth")
+			os.Exit(1)
+		}
+	}
 	// Start the health endpoints
-	setupChecks(mgr)
+	setupChecks(mgr, dexProber, !enableWebhooks)
 	setupLog.Info("started health check endpoints", "addr", healthAddr)
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager")
@@ -119,14 +324,44 @@ func main() {
 	}
 }
 
-func setupChecks(mgr ctrl.Manager) {
+// setupChecks adds the health endpoints. The dex readiness check is left out when
+// the operator serves the webhooks, they must keep answering while Dex is down.
+func setupChecks(mgr ctrl.Manager, dexProber *dexcontroller.DexProber, dexReadiness bool) {
 	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to create ready check")
 		os.Exit(1)
 	}
 
+	// The operator is not ready while the default dex instance is unavailable
+	if dexReadiness {
+		if err := mgr.AddReadyzCheck("dex", dexProber.Check); err != nil {
+			setupLog.Error(err, "unable to create dex ready check")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to create health check")
 		os.Exit(1)
 	}
 }
+
+// serveTraces serves the traces of the Dex GRPC calls until the manager stops, like
+// all golang.org/x/net/trace pages they are only shown to requests from localhost
+func serveTraces(addr string) manager.RunnableFunc {
+	return func(stop <-chan struct{}) error {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/requests", trace.Traces)
+		mux.HandleFunc("/debug/events", trace.Events)
+		server := &http.Server{Addr: addr, Handler: mux}
+		go func() {
+			<-stop
+			_ = server.Close()
+		}()
+		setupLog.Info("serving Dex GRPC traces", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupported is returned for RPCs the Dex server does not implement
+var ErrUnsupported = errors.New("not supported by the dex server")
+
+// Capability is an optional RPC of the Dex API
+type Capability string
+
+// Capabilities which are not served by every Dex version
+const (
+	// CapabilityGetClient reads a single client, needed for drift detection and adoption
+	CapabilityGetClient Capability = "GetClient"
+	// CapabilityRefreshTokens lists and revokes the refresh tokens of a user
+	CapabilityRefreshTokens Capability = "RefreshTokens"
+	// CapabilityVerifyPassword checks a password of the local connector
+	CapabilityVerifyPassword Capability = "VerifyPassword"
+)
+
+// capabilityAPILevels are the API levels from which on Dex serves a capability.
+// GetClient was added without raising the API level, it is assumed to be served
+// until Dex answers Unimplemented.
+var capabilityAPILevels = map[Capability]int32{
+	CapabilityGetClient:      0,
+	CapabilityRefreshTokens:  1,
+	CapabilityVerifyPassword: 2,
+}
+
+// Capabilities is the set of optional RPCs a Dex server supports
+type Capabilities struct {
+	// Negotiated is false until the API level was read from the server, all
+	// capabilities are assumed before
+	Negotiated bool
+	// Server is the version of the Dex server
+	Server string
+	// APILevel is the API level reported by the server
+	APILevel int32
+
+	// unimplemented are the capabilities the server answered Unimplemented for
+	unimplemented map[Capability]bool
+}
+
+// Supports returns true when the server is known or assumed to serve the capability
+func (c Capabilities) Supports(capability Capability) bool {
+	if c.unimplemented[capability] {
+		return false
+	}
+	return !c.Negotiated || c.APILevel >= capabilityAPILevels[capability]
+}
+
+// Unsupported returns the sorted capabilities the server does not serve
+func (c Capabilities) Unsupported() []string {
+	var unsupported []string
+	for capability := range capabilityAPILevels {
+		if !c.Supports(capability) {
+			unsupported = append(unsupported, string(capability))
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// Negotiate reads the API level of the server and returns its capabilities
+func (c *APIClient) Negotiate(ctx context.Context) (Capabilities, error) {
+	if _, err := c.GetVersion(ctx); err != nil {
+		return c.Capabilities(), err
+	}
+	return c.Capabilities(), nil
+}
+
+// Capabilities returns the capabilities of the server as last negotiated
+func (c *APIClient) Capabilities() Capabilities {
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+	caps := c.caps
+	caps.unimplemented = make(map[Capability]bool, len(c.caps.unimplemented))
+	for capability, unimplemented := range c.caps.unimplemented {
+		caps.unimplemented[capability] = unimplemented
+	}
+	return caps
+}
+
+// Supports returns true when the server is known or assumed to serve the capability
+func (c *APIClient) Supports(capability Capability) bool {
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+	return c.caps.Supports(capability)
+}
+
+// negotiated records the version of the server, capabilities found unimplemented
+// are tried again once the server is upgraded
+func (c *APIClient) negotiated(version ServerVersion) {
+	c.capsMu.Lock()
+	defer c.capsMu.Unlock()
+	if c.caps.Server != version.Server || c.caps.APILevel != version.API {
+		c.caps.unimplemented = nil
+	}
+	c.caps.Negotiated = true
+	c.caps.Server = version.Server
+	c.caps.APILevel = version.API
+}
+
+// unimplemented records that the server answered Unimplemented for the capability
+// and returns an error wrapping ErrUnsupported
+func (c *APIClient) unimplemented(capability Capability, err error) error {
+	c.capsMu.Lock()
+	if c.caps.unimplemented == nil {
+		c.caps.unimplemented = map[Capability]bool{}
+	}
+	c.caps.unimplemented[capability] = true
+	c.capsMu.Unlock()
+	return unsupported(capability, err)
+}
+
+// unsupported returns an error wrapping ErrUnsupported for the capability
+func unsupported(capability Capability, err error) error {
+	return &unsupportedError{capability: capability, err: err}
+}
+
+// unsupportedError wraps ErrUnsupported and keeps the error of the server
+type unsupportedError struct {
+	capability Capability
+	err        error
+}
+
+func (e *unsupportedError) Error() string {
+	if e.err == nil {
+		return string(e.capability) + " is " + ErrUnsupported.Error()
+	}
+	return string(e.capability) + " is " + ErrUnsupported.Error() + ": " + e.err.Error()
+}
+
+// Is lets errors.Is match ErrUnsupported
+func (e *unsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// Unwrap returns the error of the server
+func (e *unsupportedError) Unwrap() error {
+	return e.err
+}
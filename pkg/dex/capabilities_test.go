@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+)
+
+var _ = Describe("Capabilities", func() {
+	var server *dextest.Server
+	var dex *dexapi.APIClient
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = dextest.NewServer()
+		var err error
+		dex, err = server.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(dex.Close()).To(Succeed())
+		server.Stop()
+	})
+
+	It("should report unimplemented calls as unsupported", func() {
+		_, err := dex.VerifyPassword(ctx, "admin@example.com", "password")
+		Expect(errors.Is(err, dexapi.ErrUnsupported)).To(BeTrue())
+		Expect(dex.Supports(dexapi.CapabilityVerifyPassword)).To(BeFalse())
+		Expect(dex.Capabilities().Unsupported()).To(ConsistOf(string(dexapi.CapabilityVerifyPassword)))
+	})
+
+	It("should report the configured version", func() {
+		server.SetVersion("v2.10.0", 0)
+		caps, err := dex.Negotiate(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caps.Server).To(Equal("v2.10.0"))
+		Expect(caps.Supports(dexapi.CapabilityRefreshTokens)).To(BeFalse())
+		_, err = dex.ListRefreshTokens(ctx, "1")
+		Expect(errors.Is(err, dexapi.ErrUnsupported)).To(BeTrue())
+		Expect(server.Calls("ListRefresh")).To(BeZero())
+	})
+})
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+// Certificate describes the client certificate used for the connection to Dex
+type Certificate struct {
+	// Subject of the certificate
+	Subject string
+	// SerialNumber identifies the certificate, it changes when the certificate is renewed
+	SerialNumber string
+	// NotAfter is the expiry of the certificate
+	NotAfter time.Time
+}
+
+// tlsMaterial is a parsed CA, client certificate and key
+type tlsMaterial struct {
+	caPool      *x509.CertPool
+	keyPair     tls.Certificate
+	certificate Certificate
+}
+
+// parseTLSMaterial parses PEM encoded certificates and key
+func parseTLSMaterial(caCert, clientCert, clientKey []byte) (*tlsMaterial, error) {
+	certPool := x509.NewCertPool()
+	appended := certPool.AppendCertsFromPEM(caCert)
+	if !appended {
+		return nil, errors.New("failed to append the CA cert to the certs pool")
+	}
+
+	keyPair, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading the client cert and private key")
+	}
+	leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing the client cert")
+	}
+	keyPair.Leaf = leaf
+	return &tlsMaterial{
+		caPool:  certPool,
+		keyPair: keyPair,
+		certificate: Certificate{
+			Subject:      leaf.Subject.String(),
+			SerialNumber: leaf.SerialNumber.String(),
+			NotAfter:     leaf.NotAfter,
+		},
+	}, nil
+}
+
+// certSource provides the current TLS material of a connection
+type certSource interface {
+	material() (*tlsMaterial, error)
+}
+
+// staticCerts is TLS material which never changes, e.g. read from a DexServer
+// Secret which rebuilds the connection when it changes
+type staticCerts struct {
+	m *tlsMaterial
+}
+
+func (s staticCerts) material() (*tlsMaterial, error) {
+	return s.m, nil
+}
+
+// fileCerts reads the TLS material from files and reads them again once their
+// modification time changes, so certificates renewed in a mounted Secret are used
+// without a restart.
+type fileCerts struct {
+	caFile, certFile, keyFile string
+
+	mu       sync.Mutex
+	current  *tlsMaterial
+	modTimes [3]time.Time
+}
+
+// newFileCerts reads the TLS material from the files, it fails when they are
+// missing or invalid
+func newFileCerts(caFile, certFile, keyFile string) (*fileCerts, error) {
+	f := &fileCerts{caFile: caFile, certFile: certFile, keyFile: keyFile}
+	if _, err := f.material(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// material returns the TLS material, reading the files again when they changed.
+// When the files can not be read or are invalid, e.g. while they are being
+// replaced, the previous material is kept.
+func (f *fileCerts) material() (*tlsMaterial, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var modTimes [3]time.Time
+	for i, file := range []string{f.caFile, f.certFile, f.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return f.keep(errors.Wrapf(err, "reading %q", file))
+		}
+		modTimes[i] = info.ModTime()
+	}
+	if f.current != nil && modTimes == f.modTimes {
+		return f.current, nil
+	}
+
+	caCert, err := ioutil.ReadFile(f.caFile) // #nosec
+	if err != nil {
+		return f.keep(errors.Wrapf(err, "reading the public CA cert from %q", f.caFile))
+	}
+	clientCert, err := ioutil.ReadFile(f.certFile) // #nosec
+	if err != nil {
+		return f.keep(errors.Wrapf(err, "reading the client cert from %q", f.certFile))
+	}
+	clientKey, err := ioutil.ReadFile(f.keyFile) // #nosec
+	if err != nil {
+		return f.keep(errors.Wrapf(err, "reading the client key from %q", f.keyFile))
+	}
+	m, err := parseTLSMaterial(caCert, clientCert, clientKey)
+	if err != nil {
+		return f.keep(err)
+	}
+	f.current, f.modTimes = m, modTimes
+	return m, nil
+}
+
+// keep returns the previous material, or the error when there is none
+func (f *fileCerts) keep(err error) (*tlsMaterial, error) {
+	if f.current == nil {
+		return nil, err
+	}
+	return f.current, nil
+}
+
+// reloadingCredentials are TLS transport credentials built from the current
+// material of a certSource on every handshake. Established connections keep the
+// certificate they were opened with, new connections use the renewed one.
+type reloadingCredentials struct {
+	certs certSource
+}
+
+// ClientHandshake implements credentials.TransportCredentials
+func (c *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	m, err := c.certs.material()
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPair := m.keyPair
+	return credentials.NewTLS(&tls.Config{
+		RootCAs: m.caPool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &keyPair, nil
+		},
+	}).ClientHandshake(ctx, authority, rawConn)
+}
+
+// ServerHandshake implements credentials.TransportCredentials, the credentials are
+// only used by clients
+func (c *reloadingCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("reloading credentials do not support server handshakes")
+}
+
+// Info implements credentials.TransportCredentials
+func (c *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls", SecurityVersion: "1.2"}
+}
+
+// Clone implements credentials.TransportCredentials
+func (c *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return &reloadingCredentials{certs: c.certs}
+}
+
+// OverrideServerName implements credentials.TransportCredentials, the server name
+// is taken from the authority of the connection
+func (c *reloadingCredentials) OverrideServerName(string) error {
+	return nil
+}
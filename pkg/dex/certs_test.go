@@ -0,0 +1,202 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testCA issues the certificates of the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA() *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dex-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM encoded certificate and key signed by the CA
+func (ca *testCA) issue(commonName string, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("fileCerts", func() {
+	var ca *testCA
+	var dir, caFile, certFile, keyFile string
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// write writes the CA and a client certificate with the serial number, all
+	// files are given the modification time
+	write := func(serial int64, modTime time.Time) {
+		cert, key := ca.issue("dex-operator", serial, x509.ExtKeyUsageClientAuth)
+		for file, data := range map[string][]byte{caFile: ca.pem, certFile: cert, keyFile: key} {
+			Expect(ioutil.WriteFile(file, data, 0600)).To(Succeed())
+			Expect(os.Chtimes(file, modTime, modTime)).To(Succeed())
+		}
+	}
+	serial := func(certs certSource) string {
+		m, err := certs.material()
+		Expect(err).NotTo(HaveOccurred())
+		return m.certificate.SerialNumber
+	}
+
+	BeforeEach(func() {
+		ca = newTestCA()
+		var err error
+		dir, err = ioutil.TempDir("", "dex-certs")
+		Expect(err).NotTo(HaveOccurred())
+		caFile, certFile, keyFile = filepath.Join(dir, "ca.crt"), filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+		write(1, modTime)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should read the files again once their modification time changed", func() {
+		certs, err := newFileCerts(caFile, certFile, keyFile)
+		Expect(err).NotTo(HaveOccurred())
+		m, err := certs.material()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.certificate.Subject).To(Equal("CN=dex-operator"))
+		Expect(m.certificate.SerialNumber).To(Equal("1"))
+
+		write(2, modTime)
+		Expect(serial(certs)).To(Equal("1"))
+		write(2, modTime.Add(time.Minute))
+		Expect(serial(certs)).To(Equal("2"))
+	})
+
+	It("should keep the previous material while the files can not be read", func() {
+		certs, err := newFileCerts(caFile, certFile, keyFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.Remove(keyFile)).To(Succeed())
+		Expect(serial(certs)).To(Equal("1"))
+		Expect(ioutil.WriteFile(keyFile, []byte("half written"), 0600)).To(Succeed())
+		Expect(serial(certs)).To(Equal("1"))
+
+		write(2, modTime.Add(time.Minute))
+		Expect(serial(certs)).To(Equal("2"))
+	})
+
+	It("should fail without previous material", func() {
+		_, err := newFileCerts(caFile, certFile, filepath.Join(dir, "missing.key"))
+		Expect(err).To(MatchError(ContainSubstring("missing.key")))
+		Expect(ioutil.WriteFile(caFile, []byte("not a certificate"), 0600)).To(Succeed())
+		_, err = newFileCerts(caFile, certFile, keyFile)
+		Expect(err).To(MatchError(ContainSubstring("CA cert")))
+	})
+
+	Describe("reloadingCredentials", func() {
+		// handshake opens a TLS connection to a server requiring a client
+		// certificate and returns the serial number of the certificate it received
+		handshake := func(creds *reloadingCredentials) string {
+			serverCert, serverKey := ca.issue("dex", 100, x509.ExtKeyUsageServerAuth)
+			keyPair, err := tls.X509KeyPair(serverCert, serverKey)
+			Expect(err).NotTo(HaveOccurred())
+			clientCAs := x509.NewCertPool()
+			clientCAs.AddCert(ca.cert)
+
+			clientConn, serverConn := net.Pipe()
+			defer clientConn.Close()
+			defer serverConn.Close()
+			received := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn := tls.Server(serverConn, &tls.Config{
+					Certificates: []tls.Certificate{keyPair},
+					ClientAuth:   tls.RequireAndVerifyClientCert,
+					ClientCAs:    clientCAs,
+					NextProtos:   []string{"h2"},
+				})
+				Expect(conn.Handshake()).To(Succeed())
+				received <- conn.ConnectionState().PeerCertificates[0].SerialNumber.String()
+			}()
+			_, _, err = creds.ClientHandshake(context.Background(), "dex", clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			var serial string
+			Eventually(received).Should(Receive(&serial))
+			return serial
+		}
+
+		It("should open new connections with the renewed certificate", func() {
+			certs, err := newFileCerts(caFile, certFile, keyFile)
+			Expect(err).NotTo(HaveOccurred())
+			creds := &reloadingCredentials{certs: certs}
+			Expect(handshake(creds)).To(Equal("1"))
+
+			write(2, modTime.Add(time.Minute))
+			Expect(handshake(creds)).To(Equal("2"))
+			Expect(handshake(creds.Clone().(*reloadingCredentials))).To(Equal("2"))
+		})
+
+		It("should only be used by clients", func() {
+			_, _, err := (&reloadingCredentials{}).ServerHandshake(nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
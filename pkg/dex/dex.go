@@ -17,14 +17,33 @@ package api
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	stderrors "errors"
 	"fmt"
-	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// recreateAttempts is the number of create attempts after deleting a client in RecreateClient
+	recreateAttempts = 3
+	// recreateBackoff is the delay between create attempts in RecreateClient
+	recreateBackoff = 500 * time.Millisecond
+)
+
+var (
+	// ErrNotFound is returned when Dex does not know the requested object
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when creating an object which already exists in Dex
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrNotDeleted is returned by RecreateClient when the client could not be
+	// deleted, it is then left unchanged in Dex
+	ErrNotDeleted = errors.New("not deleted")
 )
 
 // Options keeps some configuration options for Dex client
@@ -37,115 +56,293 @@ type Options struct {
 	ClientKey string
 	// ClientCA self signed CA certificate for gRPC TLS connection
 	ClientCA string
+
+	// Timeout is the deadline of calls without an earlier one, zero disables it
+	Timeout time.Duration
+	// Retries is the number of retries of idempotent calls failing with a
+	// transient error, e.g. Dex being unavailable
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled on every further retry
+	RetryBackoff time.Duration
+	// KeepaliveTime is the interval of keepalive pings while calls are in flight,
+	// zero disables them. Dex closes connections pinging more often than every 5
+	// minutes by default.
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is the time to wait for the answer to a keepalive ping
+	KeepaliveTimeout time.Duration
+	// Interceptors run around every attempt of a call, e.g. for logging, metrics
+	// or tracing
+	Interceptors []grpc.UnaryClientInterceptor
 }
 
 // APIClient represent a client wrapper for Dex
 type APIClient struct {
-	dex DexClient
+	dex   DexClient
+	conn  *grpc.ClientConn
+	certs certSource
+
+	capsMu sync.Mutex
+	caps   Capabilities
 }
 
-// NewClient creates a new Dex client
+// NewClient creates a new Dex client. The certificates are read again when the
+// files change, e.g. when cert-manager renews them in the mounted Secret.
 func NewClient(opts *Options) (*APIClient, error) {
-	certPool := x509.NewCertPool()
-	caCert, err := ioutil.ReadFile(opts.ClientCA) // #nosec
+	certs, err := newFileCerts(opts.ClientCA, opts.ClientCrt, opts.ClientKey)
 	if err != nil {
-		return nil, errors.Wrapf(err, "reading the public CA cert from %q", opts.ClientCA)
-	}
-	appended := certPool.AppendCertsFromPEM(caCert)
-	if !appended {
-		return nil, errors.New("failed to append the CA cert to the certs pool")
+		return nil, err
 	}
+	return dial(opts, certs)
+}
 
-	clientCert, err := tls.LoadX509KeyPair(opts.ClientCrt, opts.ClientKey)
+// NewClientFromPEM creates a new Dex client from PEM encoded certificates and key,
+// the certificate paths of opts are not used
+func NewClientFromPEM(opts *Options, caCert, clientCert, clientKey []byte) (*APIClient, error) {
+	m, err := parseTLSMaterial(caCert, clientCert, clientKey)
 	if err != nil {
-		return nil, errors.Wrapf(err, "loading the client cert %q and private key %q",
-			opts.ClientCrt, opts.ClientKey)
+		return nil, err
 	}
+	return dial(opts, staticCerts{m: m})
+}
 
-	clientTLSConfig := &tls.Config{
-		RootCAs:      certPool,
-		Certificates: []tls.Certificate{clientCert},
+// NewClientFromConn creates a new Dex client on an open connection, e.g. to a fake
+// Dex server in tests. The client has no certificate, timeouts and retries are
+// set by the dial options of the connection, see DialOptions.
+func NewClientFromConn(conn *grpc.ClientConn) *APIClient {
+	return &APIClient{
+		dex:  NewDexClient(conn),
+		conn: conn,
 	}
-	creds := credentials.NewTLS(clientTLSConfig)
+}
 
-	conn, err := grpc.Dial(opts.HostAndPort, grpc.WithTransportCredentials(creds))
+// dial opens the connection to Dex with the TLS material of certs
+func dial(opts *Options, certs certSource) (*APIClient, error) {
+	creds := &reloadingCredentials{certs: certs}
+	dialOptions := append(DialOptions(opts), grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(opts.HostAndPort, dialOptions...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "opening the gRPC connection with server %q", opts.HostAndPort)
 	}
 	return &APIClient{
-		dex: NewDexClient(conn),
+		dex:   NewDexClient(conn),
+		conn:  conn,
+		certs: certs,
 	}, nil
 }
 
+// Certificate returns the client certificate new connections to Dex are opened
+// with, reading it again when it changed on disk
+func (c *APIClient) Certificate() (Certificate, error) {
+	if c.certs == nil {
+		return Certificate{}, errors.New("the client has no certificate")
+	}
+	m, err := c.certs.material()
+	if err != nil {
+		return Certificate{}, err
+	}
+	return m.certificate, nil
+}
+
+// Close closes the connection to Dex
+func (c *APIClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// ServerVersion is the version reported by Dex
+type ServerVersion struct {
+	// Server is the version of the Dex server
+	Server string
+	// API is the version of the gRPC API, it is increased when the API changes
+	API int32
+}
+
 // GetServerInfo returns server info
 func (c *APIClient) GetServerInfo(ctx context.Context) (string, error) {
+	version, err := c.GetVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return version.Server, nil
+}
+
+// GetVersion returns the version of the Dex server and its API, the capabilities
+// of the client follow the reported API level
+func (c *APIClient) GetVersion(ctx context.Context) (ServerVersion, error) {
 	req := &VersionReq{}
 	res, err := c.dex.GetVersion(ctx, req)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to to get DEX version")
+		return ServerVersion{}, errors.Wrap(err, "failed to to get DEX version")
 	}
-	return res.Server, nil
+	version := ServerVersion{Server: res.Server, API: res.Api}
+	c.negotiated(version)
+	return version, nil
 }
 
-// CreateClient a new OIDC client in Dex
-func (c *APIClient) CreateClient(ctx context.Context, redirectUris []string, trustedPeers []string,
-	public bool, name string, id string, logoURL string, secret string) (*Client, error) {
-	req := &CreateClientReq{
-		Client: &Client{
-			RedirectUris: redirectUris,
-			TrustedPeers: trustedPeers,
-			Public:       public,
-			Name:         name,
-			LogoUrl:      logoURL,
-			Secret:       secret,
-			Id:           id,
-		},
+// GetClient returns the OIDC client with the given id, it returns an error wrapping
+// ErrNotFound when the client does not exist and ErrUnsupported when the server
+// does not serve GetClient.
+func (c *APIClient) GetClient(ctx context.Context, id string) (*Client, error) {
+	if !c.Supports(CapabilityGetClient) {
+		return nil, unsupported(CapabilityGetClient, nil)
 	}
+	res, err := c.dex.GetClient(ctx, &GetClientReq{Id: id})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("client %q: %w", id, ErrNotFound)
+		}
+		if status.Code(err) == codes.Unimplemented {
+			return nil, c.unimplemented(CapabilityGetClient, err)
+		}
+		return nil, errors.Wrapf(err, "failed to get the client with id %q", id)
+	}
+	return res.Client, nil
+}
 
-	res, err := c.dex.CreateClient(ctx, req)
+// isNotFound returns true for not found errors, Dex returns the storage error
+// without a status code when a client is missing.
+func isNotFound(err error) bool {
+	s := status.Convert(err)
+	return s.Code() == codes.NotFound ||
+		(s.Code() == codes.Unknown && strings.Contains(s.Message(), "not found"))
+}
+
+// IsPermanent returns true for errors which retrying the same request does not fix,
+// like invalid arguments or conflicting clients. Other errors, e.g. Dex being
+// unavailable or deadlines being exceeded, are transient.
+func IsPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+	if stderrors.Is(err, ErrAlreadyExists) || stderrors.Is(err, ErrUnsupported) {
+		return true
+	}
+	switch grpcCode(err) {
+	case codes.InvalidArgument, codes.AlreadyExists, codes.FailedPrecondition,
+		codes.PermissionDenied, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+// grpcCode returns the status code of the first gRPC error in the chain of wrapped errors
+func grpcCode(err error) codes.Code {
+	for err != nil {
+		if s, ok := status.FromError(err); ok {
+			return s.Code()
+		}
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			err = e.Cause()
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return codes.Unknown
+		}
+	}
+	return codes.OK
+}
+
+// notDeletedError wraps ErrNotDeleted and keeps the error of the server
+type notDeletedError struct {
+	id  string
+	err error
+}
+
+func (e *notDeletedError) Error() string {
+	return fmt.Sprintf("client %q was %s: %s", e.id, ErrNotDeleted.Error(), e.err.Error())
+}
+
+// Is lets errors.Is match ErrNotDeleted
+func (e *notDeletedError) Is(target error) bool {
+	return target == ErrNotDeleted
+}
+
+// Unwrap returns the error of the server
+func (e *notDeletedError) Unwrap() error {
+	return e.err
+}
+
+// CreateClient creates a new OIDC client in Dex, it returns an error wrapping
+// ErrAlreadyExists when a client with the same ID exists.
+func (c *APIClient) CreateClient(ctx context.Context, req CreateClientRequest) (*Client, error) {
+	res, err := c.dex.CreateClient(ctx, &CreateClientReq{Client: req.client()})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create the OIDC client")
 	}
-
 	if res.AlreadyExists {
-		return nil, errors.Errorf("client %q already exists", id)
+		return nil, fmt.Errorf("client %q: %w", req.ID, ErrAlreadyExists)
 	}
-
 	return res.Client, nil
 }
 
-// UpdateClient updates an already registered OIDC client
-func (c *APIClient) UpdateClient(ctx context.Context, clientID string, redirectUris []string,
-	trustedPeers []string, public bool, name string, logoURL string) error {
-	req := &UpdateClientReq{
-		Id:           clientID,
-		RedirectUris: redirectUris,
-		TrustedPeers: trustedPeers,
-		Name:         name,
-		LogoUrl:      logoURL,
-	}
-	res, err := c.dex.UpdateClient(ctx, req)
+// UpdateClient updates an already registered OIDC client, it returns an error
+// wrapping ErrNotFound when the client does not exist. Dex can not update the
+// secret or the public flag in place, use RecreateClient to change them.
+func (c *APIClient) UpdateClient(ctx context.Context, req UpdateClientRequest) error {
+	res, err := c.dex.UpdateClient(ctx, &UpdateClientReq{
+		Id:           req.ID,
+		RedirectUris: req.RedirectURIs,
+		TrustedPeers: req.TrustedPeers,
+		Name:         req.Name,
+		LogoUrl:      req.LogoURL,
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to update the client with id %q", clientID)
+		return errors.Wrapf(err, "failed to update the client with id %q", req.ID)
 	}
-
 	if res.NotFound {
-		return fmt.Errorf("update did not find the client with id %q", clientID)
+		return fmt.Errorf("update client %q: %w", req.ID, ErrNotFound)
 	}
 	return nil
 }
 
-// DeleteClient deletes the client with given Id from Dex
+// DeleteClient deletes the client with given Id from Dex, it returns an error
+// wrapping ErrNotFound when the client does not exist.
 func (c *APIClient) DeleteClient(ctx context.Context, id string) error {
-	req := &DeleteClientReq{
-		Id: id,
-	}
-	res, err := c.dex.DeleteClient(ctx, req)
+	res, err := c.dex.DeleteClient(ctx, &DeleteClientReq{Id: id})
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete the client with id %q", id)
 	}
 	if res.NotFound {
-		return fmt.Errorf("delete did not find the client with id %q", id)
+		return fmt.Errorf("delete client %q: %w", id, ErrNotFound)
 	}
 	return nil
 }
+
+// RecreateClient replaces the client with the same ID. UpdateClientReq can not change
+// the secret or public flag of a client, so they can only be changed by deleting and
+// creating the client again. A failed delete returns an error wrapping ErrNotDeleted,
+// the client is then unchanged. A failed create is retried as the client is missing
+// in Dex until it succeeds, unless the error is permanent. A retry finding the client
+// takes it as created by the attempt whose response was lost.
+func (c *APIClient) RecreateClient(ctx context.Context, req CreateClientRequest) error {
+	if req.ID == "" {
+		return errors.New("refusing to recreate a client without id")
+	}
+	// A client which is already gone is simply created again
+	if _, err := c.dex.DeleteClient(ctx, &DeleteClientReq{Id: req.ID}); err != nil {
+		return &notDeletedError{id: req.ID, err: err}
+	}
+	var err error
+	for attempt := 1; attempt <= recreateAttempts; attempt++ {
+		var res *CreateClientResp
+		res, err = c.dex.CreateClient(ctx, &CreateClientReq{Client: req.client()})
+		if err == nil {
+			if res.AlreadyExists && attempt == 1 {
+				return errors.Errorf("client %q was created concurrently", req.ID)
+			}
+			return nil
+		}
+		if attempt == recreateAttempts || IsPermanent(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "client %q was deleted but not created again", req.ID)
+		case <-time.After(recreateBackoff * time.Duration(attempt)):
+		}
+	}
+	return errors.Wrapf(err, "client %q was deleted but not created again", req.ID)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+)
+
+var _ = Describe("RecreateClient", func() {
+	var server *dextest.Server
+	var dex *dexapi.APIClient
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = dextest.NewServer()
+		var err error
+		dex, err = server.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(dex.Close()).To(Succeed())
+		server.Stop()
+	})
+
+	It("should keep the client when recreating fails to delete it", func() {
+		server.AddClient(&dexapi.Client{Id: "web", Secret: "old"})
+		server.Inject(dextest.Fault{Method: "DeleteClient", Code: codes.Unavailable, Times: 1})
+		err := dex.RecreateClient(ctx, dexapi.CreateClientRequest{ID: "web", Secret: "new"})
+		Expect(errors.Is(err, dexapi.ErrNotDeleted)).To(BeTrue())
+		Expect(dexapi.IsPermanent(err)).To(BeFalse())
+		Expect(server.Client("web").GetSecret()).To(Equal("old"))
+		Expect(server.Calls("CreateClient")).To(BeZero())
+	})
+
+	It("should not retry permanent errors when recreating", func() {
+		server.AddClient(&dexapi.Client{Id: "web", Secret: "old"})
+		server.Inject(dextest.Fault{Method: "CreateClient", Code: codes.InvalidArgument})
+		err := dex.RecreateClient(ctx, dexapi.CreateClientRequest{ID: "web", Secret: "new"})
+		Expect(errors.Is(err, dexapi.ErrNotDeleted)).To(BeFalse())
+		Expect(dexapi.IsPermanent(err)).To(BeTrue())
+		Expect(server.Calls("CreateClient")).To(Equal(1))
+	})
+
+	It("should take the client found by a retry when recreating as created", func() {
+		server.AddClient(&dexapi.Client{Id: "web", Secret: "old"})
+		server.Inject(dextest.Fault{Method: "CreateClient", Code: codes.Unavailable, Lost: true, Times: 1})
+		Expect(dex.RecreateClient(ctx, dexapi.CreateClientRequest{ID: "web", Secret: "new"})).To(Succeed())
+		Expect(server.Client("web").GetSecret()).To(Equal("new"))
+		Expect(server.Calls("CreateClient")).To(Equal(2))
+	})
+})
@@ -0,0 +1,435 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dextest provides an in-memory Dex gRPC server for tests. It keeps
+// clients and passwords like Dex does, can be scripted to fail or delay calls and
+// lets tests inspect what was stored.
+package dextest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+const (
+	// Version is the server version reported by default
+	Version = "v2.24.0-dextest"
+	// APILevel is the API level reported by default
+	APILevel = 2
+
+	// bufferSize is the size of the in-memory connection buffer
+	bufferSize = 1024 * 1024
+)
+
+// errNotFound is the error Dex returns for missing objects, it reaches clients as
+// a status with code Unknown
+var errNotFound = errors.New("not found")
+
+// Fault scripts the response of the server to calls of an RPC
+type Fault struct {
+	// Method is the name of the RPC the fault applies to, e.g. "CreateClient",
+	// empty for all RPCs
+	Method string
+	// Latency delays the response, a call whose context ends first fails with the
+	// error of the context
+	Latency time.Duration
+	// Code fails the call with a status of the code, e.g. codes.Unavailable
+	Code codes.Code
+	// AlreadyExists answers create calls as if the object existed
+	AlreadyExists bool
+	// NotFound answers calls as if the object was missing
+	NotFound bool
+	// Lost creates the object of create calls before failing them with Code, like a
+	// response lost on its way back
+	Lost bool
+	// Times is the number of calls the fault applies to, zero for all calls
+	Times int
+}
+
+// Server is an in-memory Dex gRPC server served over bufconn, VerifyPassword is
+// not implemented
+type Server struct {
+	dexapi.UnimplementedDexServer
+
+	mu        sync.Mutex
+	version   string
+	apiLevel  int32
+	clients   map[string]*dexapi.Client
+	passwords map[string]*dexapi.Password
+	faults    []*Fault
+	calls     map[string]int
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+// NewServer starts a server, it is stopped with Stop
+func NewServer() *Server {
+	s := &Server{
+		version:   Version,
+		apiLevel:  APILevel,
+		clients:   map[string]*dexapi.Client{},
+		passwords: map[string]*dexapi.Password{},
+		calls:     map[string]int{},
+		listener:  bufconn.Listen(bufferSize),
+		server:    grpc.NewServer(),
+	}
+	dexapi.RegisterDexServer(s.server, s)
+	go func() {
+		_ = s.server.Serve(s.listener)
+	}()
+	return s
+}
+
+// Dial opens a connection to the server, opts are added to the dial options,
+// e.g. the ones of dexapi.DialOptions
+func (s *Server) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.Dial("bufnet", append([]grpc.DialOption{
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return s.listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	}, opts...)...)
+}
+
+// NewClient returns a Dex client connected to the server
+func (s *Server) NewClient(opts ...grpc.DialOption) (*dexapi.APIClient, error) {
+	conn, err := s.Dial(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return dexapi.NewClientFromConn(conn), nil
+}
+
+// Stop stops the server and closes all connections
+func (s *Server) Stop() {
+	s.server.Stop()
+}
+
+// SetVersion sets the server version and API level reported by GetVersion
+func (s *Server) SetVersion(version string, apiLevel int32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version, s.apiLevel = version, apiLevel
+}
+
+// Inject adds a fault, faults are applied in the order they were injected
+func (s *Server) Inject(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Reset removes all faults, clients, passwords and counted calls
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+	s.clients = map[string]*dexapi.Client{}
+	s.passwords = map[string]*dexapi.Password{}
+	s.calls = map[string]int{}
+}
+
+// Calls returns the number of calls of the RPC, including failed ones
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// Client returns a copy of the stored client with the ID, nil when there is none
+func (s *Server) Client(id string) *dexapi.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.clients[id]; ok {
+		return proto.Clone(c).(*dexapi.Client)
+	}
+	return nil
+}
+
+// Clients returns copies of the stored clients by ID
+func (s *Server) Clients() map[string]*dexapi.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make(map[string]*dexapi.Client, len(s.clients))
+	for id, c := range s.clients {
+		clients[id] = proto.Clone(c).(*dexapi.Client)
+	}
+	return clients
+}
+
+// AddClient stores a client as if it was created outside of the test, e.g. from
+// the Dex configuration
+func (s *Server) AddClient(c *dexapi.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c.Id] = proto.Clone(c).(*dexapi.Client)
+}
+
+// Password returns a copy of the stored password of the email, nil when there is none
+func (s *Server) Password(email string) *dexapi.Password {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.passwords[email]; ok {
+		return proto.Clone(p).(*dexapi.Password)
+	}
+	return nil
+}
+
+// Passwords returns copies of the stored passwords by email
+func (s *Server) Passwords() map[string]*dexapi.Password {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	passwords := make(map[string]*dexapi.Password, len(s.passwords))
+	for email, p := range s.passwords {
+		passwords[email] = proto.Clone(p).(*dexapi.Password)
+	}
+	return passwords
+}
+
+// call counts the call and applies the first matching fault. It returns the fault,
+// or the error the call fails with.
+func (s *Server) call(ctx context.Context, method string) (Fault, error) {
+	s.mu.Lock()
+	s.calls[method]++
+	var fault Fault
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		fault = *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if fault.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return fault, status.FromContextError(ctx.Err()).Err()
+		case <-time.After(fault.Latency):
+		}
+	}
+	if fault.Code != codes.OK {
+		return fault, status.Errorf(fault.Code, "dextest: injected %s fault", fault.Code)
+	}
+	return fault, nil
+}
+
+// GetClient implements dexapi.DexServer
+func (s *Server) GetClient(ctx context.Context, req *dexapi.GetClientReq) (*dexapi.GetClientResp, error) {
+	fault, err := s.call(ctx, "GetClient")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.clients[req.Id]
+	if !ok || fault.NotFound {
+		return nil, errNotFound
+	}
+	return &dexapi.GetClientResp{Client: proto.Clone(c).(*dexapi.Client)}, nil
+}
+
+// CreateClient implements dexapi.DexServer
+func (s *Server) CreateClient(ctx context.Context, req *dexapi.CreateClientReq) (*dexapi.CreateClientResp, error) {
+	fault, err := s.call(ctx, "CreateClient")
+	if err != nil && !fault.Lost {
+		return nil, err
+	}
+	if req.Client == nil {
+		return nil, errors.New("no client supplied")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := proto.Clone(req.Client).(*dexapi.Client)
+	if c.Id == "" {
+		c.Id = fmt.Sprintf("client-%d", len(s.clients)+1)
+	}
+	if c.Secret == "" && !c.Public {
+		c.Secret = fmt.Sprintf("secret-%s", c.Id)
+	}
+	if _, ok := s.clients[c.Id]; ok || fault.AlreadyExists {
+		return &dexapi.CreateClientResp{AlreadyExists: true}, nil
+	}
+	s.clients[c.Id] = c
+	if err != nil {
+		return nil, err
+	}
+	return &dexapi.CreateClientResp{Client: proto.Clone(c).(*dexapi.Client)}, nil
+}
+
+// UpdateClient implements dexapi.DexServer, like Dex it only changes the fields
+// which are set
+func (s *Server) UpdateClient(ctx context.Context, req *dexapi.UpdateClientReq) (*dexapi.UpdateClientResp, error) {
+	fault, err := s.call(ctx, "UpdateClient")
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, errors.New("update client: no client ID supplied")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.clients[req.Id]
+	if !ok || fault.NotFound {
+		return &dexapi.UpdateClientResp{NotFound: true}, nil
+	}
+	if req.RedirectUris != nil {
+		c.RedirectUris = req.RedirectUris
+	}
+	if req.TrustedPeers != nil {
+		c.TrustedPeers = req.TrustedPeers
+	}
+	if req.Name != "" {
+		c.Name = req.Name
+	}
+	if req.LogoUrl != "" {
+		c.LogoUrl = req.LogoUrl
+	}
+	return &dexapi.UpdateClientResp{}, nil
+}
+
+// DeleteClient implements dexapi.DexServer
+func (s *Server) DeleteClient(ctx context.Context, req *dexapi.DeleteClientReq) (*dexapi.DeleteClientResp, error) {
+	fault, err := s.call(ctx, "DeleteClient")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[req.Id]; !ok || fault.NotFound {
+		return &dexapi.DeleteClientResp{NotFound: true}, nil
+	}
+	delete(s.clients, req.Id)
+	return &dexapi.DeleteClientResp{}, nil
+}
+
+// CreatePassword implements dexapi.DexServer
+func (s *Server) CreatePassword(ctx context.Context, req *dexapi.CreatePasswordReq) (*dexapi.CreatePasswordResp, error) {
+	fault, err := s.call(ctx, "CreatePassword")
+	if err != nil {
+		return nil, err
+	}
+	if req.Password == nil || req.Password.Email == "" {
+		return nil, errors.New("no email supplied")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.passwords[req.Password.Email]; ok || fault.AlreadyExists {
+		return &dexapi.CreatePasswordResp{AlreadyExists: true}, nil
+	}
+	s.passwords[req.Password.Email] = proto.Clone(req.Password).(*dexapi.Password)
+	return &dexapi.CreatePasswordResp{}, nil
+}
+
+// UpdatePassword implements dexapi.DexServer, like Dex it only changes the fields
+// which are set
+func (s *Server) UpdatePassword(ctx context.Context, req *dexapi.UpdatePasswordReq) (*dexapi.UpdatePasswordResp, error) {
+	fault, err := s.call(ctx, "UpdatePassword")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.passwords[req.Email]
+	if !ok || fault.NotFound {
+		return &dexapi.UpdatePasswordResp{NotFound: true}, nil
+	}
+	if len(req.NewHash) > 0 {
+		p.Hash = req.NewHash
+	}
+	if req.NewUsername != "" {
+		p.Username = req.NewUsername
+	}
+	return &dexapi.UpdatePasswordResp{}, nil
+}
+
+// DeletePassword implements dexapi.DexServer
+func (s *Server) DeletePassword(ctx context.Context, req *dexapi.DeletePasswordReq) (*dexapi.DeletePasswordResp, error) {
+	fault, err := s.call(ctx, "DeletePassword")
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.passwords[req.Email]; !ok || fault.NotFound {
+		return &dexapi.DeletePasswordResp{NotFound: true}, nil
+	}
+	delete(s.passwords, req.Email)
+	return &dexapi.DeletePasswordResp{}, nil
+}
+
+// ListPasswords implements dexapi.DexServer, like Dex it does not return the hashes
+func (s *Server) ListPasswords(ctx context.Context, _ *dexapi.ListPasswordReq) (*dexapi.ListPasswordResp, error) {
+	if _, err := s.call(ctx, "ListPasswords"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := &dexapi.ListPasswordResp{}
+	for _, p := range s.passwords {
+		res.Passwords = append(res.Passwords, &dexapi.Password{
+			Email:    p.Email,
+			Username: p.Username,
+			UserId:   p.UserId,
+		})
+	}
+	return res, nil
+}
+
+// GetVersion implements dexapi.DexServer
+func (s *Server) GetVersion(ctx context.Context, _ *dexapi.VersionReq) (*dexapi.VersionResp, error) {
+	if _, err := s.call(ctx, "GetVersion"); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &dexapi.VersionResp{Server: s.version, Api: s.apiLevel}, nil
+}
+
+// ListRefresh implements dexapi.DexServer, the server keeps no refresh tokens
+func (s *Server) ListRefresh(ctx context.Context, _ *dexapi.ListRefreshReq) (*dexapi.ListRefreshResp, error) {
+	if _, err := s.call(ctx, "ListRefresh"); err != nil {
+		return nil, err
+	}
+	return &dexapi.ListRefreshResp{}, nil
+}
+
+// RevokeRefresh implements dexapi.DexServer, the server keeps no refresh tokens
+func (s *Server) RevokeRefresh(ctx context.Context, _ *dexapi.RevokeRefreshReq) (*dexapi.RevokeRefreshResp, error) {
+	if _, err := s.call(ctx, "RevokeRefresh"); err != nil {
+		return nil, err
+	}
+	return &dexapi.RevokeRefreshResp{NotFound: true}, nil
+}
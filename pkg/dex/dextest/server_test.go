@@ -0,0 +1,132 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dextest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+)
+
+var _ = Describe("Server", func() {
+	var server *Server
+	var dex *dexapi.APIClient
+	ctx := context.Background()
+	cli := dexapi.CreateClientRequest{ID: "cli", Name: "CLI", Public: true}
+
+	BeforeEach(func() {
+		server = NewServer()
+		var err error
+		dex, err = server.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(dex.Close()).To(Succeed())
+		server.Stop()
+	})
+
+	It("should keep clients like dex", func() {
+		_, err := dex.CreateClient(ctx, dexapi.CreateClientRequest{
+			ID:           "grafana",
+			Secret:       "secret",
+			Name:         "Grafana",
+			RedirectURIs: []string{"https://grafana/login"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = dex.CreateClient(ctx, dexapi.CreateClientRequest{ID: "grafana", Secret: "secret", Name: "Grafana"})
+		Expect(errors.Is(err, dexapi.ErrAlreadyExists)).To(BeTrue())
+
+		Expect(dex.UpdateClient(ctx, dexapi.UpdateClientRequest{ID: "grafana", LogoURL: "https://grafana/logo.png"})).To(Succeed())
+		live, err := dex.GetClient(ctx, "grafana")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(live.RedirectUris).To(ConsistOf("https://grafana/login"))
+		Expect(live.LogoUrl).To(Equal("https://grafana/logo.png"))
+		Expect(server.Client("grafana").Secret).To(Equal("secret"))
+
+		Expect(dex.DeleteClient(ctx, "grafana")).To(Succeed())
+		_, err = dex.GetClient(ctx, "grafana")
+		Expect(errors.Is(err, dexapi.ErrNotFound)).To(BeTrue())
+		Expect(server.Clients()).To(BeEmpty())
+		Expect(errors.Is(dex.DeleteClient(ctx, "grafana"), dexapi.ErrNotFound)).To(BeTrue())
+		err = dex.UpdateClient(ctx, dexapi.UpdateClientRequest{ID: "grafana"})
+		Expect(errors.Is(err, dexapi.ErrNotFound)).To(BeTrue())
+	})
+
+	It("should keep passwords like dex", func() {
+		password := dexapi.CreatePasswordRequest{Email: "admin@example.com", Hash: []byte("$2a$10$hash"), Username: "admin", UserID: "1"}
+		Expect(dex.CreatePassword(ctx, password)).To(Succeed())
+		Expect(errors.Is(dex.CreatePassword(ctx, password), dexapi.ErrAlreadyExists)).To(BeTrue())
+
+		Expect(dex.UpdatePassword(ctx, dexapi.UpdatePasswordRequest{Email: "admin@example.com", NewUsername: "root"})).To(Succeed())
+		passwords, err := dex.ListPasswords(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(passwords).To(HaveLen(1))
+		Expect(passwords[0].Username).To(Equal("root"))
+		Expect(passwords[0].Hash).To(BeEmpty())
+		Expect(server.Password("admin@example.com").Hash).To(Equal(password.Hash))
+
+		Expect(dex.DeletePassword(ctx, "admin@example.com")).To(Succeed())
+		Expect(errors.Is(dex.DeletePassword(ctx, "admin@example.com"), dexapi.ErrNotFound)).To(BeTrue())
+		err = dex.UpdatePassword(ctx, dexapi.UpdatePasswordRequest{Email: "admin@example.com"})
+		Expect(errors.Is(err, dexapi.ErrNotFound)).To(BeTrue())
+	})
+
+	It("should list and revoke refresh tokens", func() {
+		tokens, err := dex.ListRefreshTokens(ctx, "1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tokens).To(BeEmpty())
+		Expect(errors.Is(dex.RevokeRefreshToken(ctx, "1", "cli"), dexapi.ErrNotFound)).To(BeTrue())
+	})
+
+	It("should apply faults the given number of times", func() {
+		server.Inject(Fault{Method: "CreateClient", Code: codes.Unavailable, Times: 1})
+		_, err := dex.CreateClient(ctx, cli)
+		Expect(status.Code(pkgerrors.Cause(err))).To(Equal(codes.Unavailable))
+		Expect(dexapi.IsPermanent(err)).To(BeFalse())
+		_, err = dex.CreateClient(ctx, cli)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.Calls("CreateClient")).To(Equal(2))
+	})
+
+	It("should answer as if objects existed or were missing", func() {
+		server.Inject(Fault{Method: "CreateClient", AlreadyExists: true, Times: 1})
+		_, err := dex.CreateClient(ctx, cli)
+		Expect(errors.Is(err, dexapi.ErrAlreadyExists)).To(BeTrue())
+
+		server.AddClient(&dexapi.Client{Id: "static", Name: "Static"})
+		server.Inject(Fault{Method: "DeleteClient", NotFound: true})
+		Expect(errors.Is(dex.DeleteClient(ctx, "static"), dexapi.ErrNotFound)).To(BeTrue())
+		Expect(server.Client("static")).NotTo(BeNil())
+	})
+
+	It("should delay responses", func() {
+		server.Inject(Fault{Latency: time.Second})
+		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err := dex.GetVersion(timeoutCtx)
+		Expect(status.Code(pkgerrors.Cause(err))).To(Equal(codes.DeadlineExceeded))
+	})
+})
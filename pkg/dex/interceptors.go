@@ -0,0 +1,181 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// idempotentMethods are the RPCs which can be retried without changing the result,
+// creating a client or password is not retried as it would fail with AlreadyExists
+var idempotentMethods = map[string]bool{
+	"/api.Dex/GetVersion":     true,
+	"/api.Dex/GetClient":      true,
+	"/api.Dex/UpdateClient":   true,
+	"/api.Dex/DeleteClient":   true,
+	"/api.Dex/UpdatePassword": true,
+	"/api.Dex/DeletePassword": true,
+	"/api.Dex/ListPasswords":  true,
+	"/api.Dex/VerifyPassword": true,
+	"/api.Dex/ListRefresh":    true,
+}
+
+// DialOptions returns the gRPC dial options for the timeouts, retries, keepalive
+// and interceptors of opts, without transport credentials. The interceptors run in
+// order around every attempt of a call.
+func DialOptions(opts *Options) []grpc.DialOption {
+	interceptors := []grpc.UnaryClientInterceptor{}
+	if opts.Retries > 0 {
+		interceptors = append(interceptors, RetryInterceptor(opts.Retries, opts.RetryBackoff))
+	}
+	if opts.Timeout > 0 {
+		interceptors = append(interceptors, TimeoutInterceptor(opts.Timeout))
+	}
+	interceptors = append(interceptors, opts.Interceptors...)
+
+	dialOptions := []grpc.DialOption{grpc.WithChainUnaryInterceptor(interceptors...)}
+	if opts.KeepaliveTime > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    opts.KeepaliveTime,
+			Timeout: opts.KeepaliveTimeout,
+		}))
+	}
+	return dialOptions
+}
+
+// TimeoutInterceptor sets the deadline of calls without an earlier one
+func TimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RetryInterceptor retries idempotent calls failing with a transient error up to
+// retries times. The backoff before the first retry is doubled on every further
+// retry. Calls are not retried once their context is done.
+func RetryInterceptor(retries int, backoff time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if !idempotentMethods[method] {
+			return err
+		}
+		delay := backoff
+		for retry := 1; retry <= retries && retryable(err); retry++ {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(delay):
+			}
+			delay *= 2
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return err
+	}
+}
+
+// retryable returns true for errors of calls which may succeed when they are sent again
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// LoggingInterceptor logs failed calls, successful calls are logged at V(1)
+func LoggingInterceptor(log logr.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		values := []interface{}{"method", method, "target", cc.Target(), "duration", time.Since(start)}
+		if err != nil {
+			log.Info("Dex call failed", append(values, "code", status.Code(err).String(), "error", err.Error())...)
+		} else {
+			log.V(1).Info("Dex call", values...)
+		}
+		return err
+	}
+}
+
+// MetricsInterceptor counts and times the calls in the metrics
+// dex_grpc_requests_total and dex_grpc_request_duration_seconds, which are
+// registered with registerer. It must only be created once per registerer.
+func MetricsInterceptor(registerer prometheus.Registerer) grpc.UnaryClientInterceptor {
+	requests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dex_grpc_requests_total",
+			Help: "Number of gRPC calls to dex by method and status code",
+		},
+		[]string{"target", "method", "code"},
+	)
+	duration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "dex_grpc_request_duration_seconds",
+			Help:    "Duration of gRPC calls to dex",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"target", "method"},
+	)
+	registerer.MustRegister(requests, duration)
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		name := strings.TrimPrefix(method, "/api.Dex/")
+		requests.WithLabelValues(cc.Target(), name, status.Code(err).String()).Inc()
+		duration.WithLabelValues(cc.Target(), name).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// TracingInterceptor records the calls as golang.org/x/net/trace traces, which are
+// served at /debug/requests by trace.Traces
+func TracingInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		tr := trace.New("dex.Sent", strings.TrimPrefix(method, "/"))
+		defer tr.Finish()
+		tr.LazyPrintf("target %s", cc.Target())
+		if deadline, ok := ctx.Deadline(); ok {
+			tr.LazyPrintf("timeout %s", time.Until(deadline))
+		}
+		err := invoker(trace.NewContext(ctx, tr), method, req, reply, cc, opts...)
+		if err != nil {
+			tr.LazyPrintf("%s", err)
+			tr.SetError()
+		}
+		return err
+	}
+}
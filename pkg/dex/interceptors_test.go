@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+)
+
+var _ = Describe("DialOptions", func() {
+	var server *dextest.Server
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = dextest.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	It("should retry idempotent calls", func() {
+		retrying, err := server.NewClient(dexapi.DialOptions(&dexapi.Options{Retries: 2, RetryBackoff: time.Millisecond})...)
+		Expect(err).NotTo(HaveOccurred())
+		defer retrying.Close()
+
+		server.AddClient(&dexapi.Client{Id: "cli", Name: "CLI", Public: true})
+		server.Inject(dextest.Fault{Method: "UpdateClient", Code: codes.Unavailable, Times: 2})
+		Expect(retrying.UpdateClient(ctx, dexapi.UpdateClientRequest{ID: "cli", Name: "Command line"})).To(Succeed())
+		Expect(server.Calls("UpdateClient")).To(Equal(3))
+
+		server.Inject(dextest.Fault{Method: "CreateClient", Code: codes.Unavailable, Times: 1})
+		_, err = retrying.CreateClient(ctx, dexapi.CreateClientRequest{ID: "web", Name: "Web", Public: true})
+		Expect(status.Code(pkgerrors.Cause(err))).To(Equal(codes.Unavailable))
+		Expect(server.Calls("CreateClient")).To(Equal(1))
+	})
+
+	It("should set a deadline on calls", func() {
+		timingOut, err := server.NewClient(dexapi.DialOptions(&dexapi.Options{Timeout: 100 * time.Millisecond})...)
+		Expect(err).NotTo(HaveOccurred())
+		defer timingOut.Close()
+
+		server.Inject(dextest.Fault{Method: "GetVersion", Latency: time.Second})
+		_, err = timingOut.GetVersion(ctx)
+		Expect(status.Code(pkgerrors.Cause(err))).To(Equal(codes.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+)
+
+// Interface is the Dex API used by the controllers. Errors wrap ErrNotFound or
+// ErrAlreadyExists when Dex reports a missing or existing object, and ErrUnsupported
+// for capabilities the server does not serve.
+type Interface interface {
+	// GetClient returns the OIDC client with the given id
+	GetClient(ctx context.Context, id string) (*Client, error)
+	// CreateClient creates a new OIDC client
+	CreateClient(ctx context.Context, req CreateClientRequest) (*Client, error)
+	// UpdateClient updates the mutable fields of an existing OIDC client
+	UpdateClient(ctx context.Context, req UpdateClientRequest) error
+	// DeleteClient deletes the OIDC client with the given id
+	DeleteClient(ctx context.Context, id string) error
+	// RecreateClient deletes and creates the client with the same id, it is the only
+	// way to change the secret or the public flag of a client. Errors wrap
+	// ErrNotDeleted when the client was left unchanged.
+	RecreateClient(ctx context.Context, req CreateClientRequest) error
+
+	// CreatePassword creates a password of the local connector
+	CreatePassword(ctx context.Context, req CreatePasswordRequest) error
+	// UpdatePassword changes the hash or the username of a password
+	UpdatePassword(ctx context.Context, req UpdatePasswordRequest) error
+	// DeletePassword deletes the password of the email
+	DeletePassword(ctx context.Context, email string) error
+	// ListPasswords returns all passwords, Dex does not return their hashes
+	ListPasswords(ctx context.Context) ([]*Password, error)
+	// VerifyPassword returns true when the password matches the one of the email
+	VerifyPassword(ctx context.Context, email, password string) (bool, error)
+
+	// ListRefreshTokens returns the refresh tokens of the user
+	ListRefreshTokens(ctx context.Context, userID string) ([]*RefreshTokenRef, error)
+	// RevokeRefreshToken revokes the refresh token of the user for the client
+	RevokeRefreshToken(ctx context.Context, userID, clientID string) error
+
+	// GetVersion returns the version of the Dex server and its API
+	GetVersion(ctx context.Context) (ServerVersion, error)
+	// Negotiate reads the API level of the server and returns its capabilities
+	Negotiate(ctx context.Context) (Capabilities, error)
+	// Capabilities returns the capabilities of the server as last negotiated
+	Capabilities() Capabilities
+	// Supports returns true when the server is known or assumed to serve the capability
+	Supports(capability Capability) bool
+
+	// Certificate returns the client certificate used for the connection
+	Certificate() (Certificate, error)
+	// Close closes the connection to Dex
+	Close() error
+}
+
+var _ Interface = &APIClient{}
+
+// CreateClientRequest describes a new OIDC client
+type CreateClientRequest struct {
+	// ID is the client ID, Dex generates one when it is empty
+	ID string
+	// Secret is the client secret, Dex generates one for confidential clients when
+	// it is empty
+	Secret string
+	// Public clients can not keep a secret, e.g. command line tools
+	Public       bool
+	Name         string
+	LogoURL      string
+	RedirectURIs []string
+	TrustedPeers []string
+}
+
+// UpdateRequest returns the update of the mutable fields of the client
+func (r CreateClientRequest) UpdateRequest() UpdateClientRequest {
+	return UpdateClientRequest{
+		ID:           r.ID,
+		Name:         r.Name,
+		LogoURL:      r.LogoURL,
+		RedirectURIs: r.RedirectURIs,
+		TrustedPeers: r.TrustedPeers,
+	}
+}
+
+// client returns the gRPC message of the client
+func (r CreateClientRequest) client() *Client {
+	return &Client{
+		Id:           r.ID,
+		Secret:       r.Secret,
+		Public:       r.Public,
+		Name:         r.Name,
+		LogoUrl:      r.LogoURL,
+		RedirectUris: r.RedirectURIs,
+		TrustedPeers: r.TrustedPeers,
+	}
+}
+
+// UpdateClientRequest describes the update of an existing OIDC client. Dex only
+// changes the fields which are set, the secret and the public flag can not be
+// updated.
+type UpdateClientRequest struct {
+	// ID is the ID of the client to update
+	ID           string
+	Name         string
+	LogoURL      string
+	RedirectURIs []string
+	TrustedPeers []string
+}
+
+// CreatePasswordRequest describes a new password of the local connector
+type CreatePasswordRequest struct {
+	Email string
+	// Hash is the bcrypt hash of the password, Dex does not accept plain text
+	Hash     []byte
+	Username string
+	UserID   string
+}
+
+// UpdatePasswordRequest describes the update of a password, Dex only changes the
+// fields which are set
+type UpdatePasswordRequest struct {
+	// Email is the email of the password to update, it can not be changed
+	Email       string
+	NewHash     []byte
+	NewUsername string
+}
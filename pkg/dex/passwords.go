@@ -0,0 +1,142 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreatePassword creates a password of the local connector, it returns an error
+// wrapping ErrAlreadyExists when the email has a password.
+func (c *APIClient) CreatePassword(ctx context.Context, req CreatePasswordRequest) error {
+	res, err := c.dex.CreatePassword(ctx, &CreatePasswordReq{
+		Password: &Password{
+			Email:    req.Email,
+			Hash:     req.Hash,
+			Username: req.Username,
+			UserId:   req.UserID,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the password of %q", req.Email)
+	}
+	if res.AlreadyExists {
+		return fmt.Errorf("password %q: %w", req.Email, ErrAlreadyExists)
+	}
+	return nil
+}
+
+// UpdatePassword changes the hash or the username of a password, it returns an
+// error wrapping ErrNotFound when the email has no password.
+func (c *APIClient) UpdatePassword(ctx context.Context, req UpdatePasswordRequest) error {
+	res, err := c.dex.UpdatePassword(ctx, &UpdatePasswordReq{
+		Email:       req.Email,
+		NewHash:     req.NewHash,
+		NewUsername: req.NewUsername,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update the password of %q", req.Email)
+	}
+	if res.NotFound {
+		return fmt.Errorf("update password %q: %w", req.Email, ErrNotFound)
+	}
+	return nil
+}
+
+// DeletePassword deletes the password of the email, it returns an error wrapping
+// ErrNotFound when the email has no password.
+func (c *APIClient) DeletePassword(ctx context.Context, email string) error {
+	res, err := c.dex.DeletePassword(ctx, &DeletePasswordReq{Email: email})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete the password of %q", email)
+	}
+	if res.NotFound {
+		return fmt.Errorf("delete password %q: %w", email, ErrNotFound)
+	}
+	return nil
+}
+
+// ListPasswords returns all passwords of the local connector without their hashes
+func (c *APIClient) ListPasswords(ctx context.Context) ([]*Password, error) {
+	res, err := c.dex.ListPasswords(ctx, &ListPasswordReq{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the passwords")
+	}
+	return res.Passwords, nil
+}
+
+// VerifyPassword returns true when the password matches the one stored for the
+// email. It returns an error wrapping ErrNotFound when the email has no password
+// and ErrUnsupported when the server does not serve VerifyPassword.
+func (c *APIClient) VerifyPassword(ctx context.Context, email, password string) (bool, error) {
+	if !c.Supports(CapabilityVerifyPassword) {
+		return false, unsupported(CapabilityVerifyPassword, nil)
+	}
+	res, err := c.dex.VerifyPassword(ctx, &VerifyPasswordReq{Email: email, Password: password})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return false, c.unimplemented(CapabilityVerifyPassword, err)
+		}
+		return false, errors.Wrapf(err, "failed to verify the password of %q", email)
+	}
+	if res.NotFound {
+		return false, fmt.Errorf("password %q: %w", email, ErrNotFound)
+	}
+	return res.Verified, nil
+}
+
+// ListRefreshTokens returns the refresh tokens of the user, identified by the sub
+// claim of its ID tokens. It returns an error wrapping ErrUnsupported when the
+// server does not serve refresh tokens.
+func (c *APIClient) ListRefreshTokens(ctx context.Context, userID string) ([]*RefreshTokenRef, error) {
+	if !c.Supports(CapabilityRefreshTokens) {
+		return nil, unsupported(CapabilityRefreshTokens, nil)
+	}
+	res, err := c.dex.ListRefresh(ctx, &ListRefreshReq{UserId: userID})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, c.unimplemented(CapabilityRefreshTokens, err)
+		}
+		return nil, errors.Wrapf(err, "failed to list the refresh tokens of %q", userID)
+	}
+	return res.RefreshTokens, nil
+}
+
+// RevokeRefreshToken revokes the refresh token of the user for the client. It
+// returns an error wrapping ErrNotFound when there is no such token and
+// ErrUnsupported when the server does not serve refresh tokens.
+func (c *APIClient) RevokeRefreshToken(ctx context.Context, userID, clientID string) error {
+	if !c.Supports(CapabilityRefreshTokens) {
+		return unsupported(CapabilityRefreshTokens, nil)
+	}
+	res, err := c.dex.RevokeRefresh(ctx, &RevokeRefreshReq{UserId: userID, ClientId: clientID})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return c.unimplemented(CapabilityRefreshTokens, err)
+		}
+		return errors.Wrapf(err, "failed to revoke the refresh token of %q for client %q", userID, clientID)
+	}
+	if res.NotFound {
+		return fmt.Errorf("refresh token of %q for client %q: %w", userID, clientID, ErrNotFound)
+	}
+	return nil
+}
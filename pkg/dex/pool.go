@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnknownServer is returned for Dex servers without a connection in the pool
+	ErrUnknownServer = errors.New("unknown dex server")
+	// ErrNotConnected is returned for the default server until its connection is built
+	ErrNotConnected = errors.New("not connected to dex yet")
+)
+
+// Pool keeps a client per named Dex server next to the default client
+type Pool struct {
+	mu      sync.RWMutex
+	def     Interface
+	clients map[string]pooledClient
+}
+
+type pooledClient struct {
+	client Interface
+	// hash identifies the configuration the client was built from
+	hash string
+}
+
+// NewPool creates a pool, the default client is returned for the empty server name.
+// It may be nil and set with SetDefault once the connection is built.
+func NewPool(defaultClient Interface) *Pool {
+	return &Pool{
+		def:     defaultClient,
+		clients: map[string]pooledClient{},
+	}
+}
+
+// Get returns the client of the named server, it returns an error wrapping
+// ErrUnknownServer when the pool has no client for it and ErrNotConnected while
+// the default client is not set.
+func (p *Pool) Get(name string) (Interface, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if name == "" {
+		if p.def == nil {
+			return nil, fmt.Errorf("default server: %w", ErrNotConnected)
+		}
+		return p.def, nil
+	}
+	pooled, ok := p.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("server %q: %w", name, ErrUnknownServer)
+	}
+	return pooled.client, nil
+}
+
+// Hash returns the configuration hash the client of the named server was built
+// from, empty when the pool has no client for it
+func (p *Pool) Hash(name string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clients[name].hash
+}
+
+// SetDefault sets the default client, replacing and closing the previous one
+func (p *Pool) SetDefault(client Interface) {
+	p.mu.Lock()
+	previous := p.def
+	p.def = client
+	p.mu.Unlock()
+	if previous != nil && previous != client {
+		_ = previous.Close()
+	}
+}
+
+// Set adds the client of the named server, replacing and closing the previous one
+func (p *Pool) Set(name string, client Interface, hash string) {
+	p.mu.Lock()
+	previous, ok := p.clients[name]
+	p.clients[name] = pooledClient{client: client, hash: hash}
+	p.mu.Unlock()
+	if ok && previous.client != client {
+		_ = previous.client.Close()
+	}
+}
+
+// Remove closes and removes the client of the named server
+func (p *Pool) Remove(name string) {
+	p.mu.Lock()
+	previous, ok := p.clients[name]
+	delete(p.clients, name)
+	p.mu.Unlock()
+	if ok {
+		_ = previous.client.Close()
+	}
+}
+
+// Clients returns a snapshot of the clients by server name, the default client is
+// returned under the empty name
+func (p *Pool) Clients() map[string]Interface {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	clients := make(map[string]Interface, len(p.clients)+1)
+	if p.def != nil {
+		clients[""] = p.def
+	}
+	for name, pooled := range p.clients {
+		clients[name] = pooled.client
+	}
+	return clients
+}
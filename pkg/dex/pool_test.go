@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Betsson Group.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	dexapi "github.com/BetssonGroup/dex-operator/pkg/dex"
+	"github.com/BetssonGroup/dex-operator/pkg/dex/dextest"
+)
+
+var _ = Describe("Pool", func() {
+	var server *dextest.Server
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = dextest.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	newClient := func() *dexapi.APIClient {
+		dex, err := server.NewClient()
+		Expect(err).NotTo(HaveOccurred())
+		return dex
+	}
+	// closed tells whether the connection of the client was closed
+	closed := func(dex *dexapi.APIClient) bool {
+		_, err := dex.GetVersion(ctx)
+		return err != nil
+	}
+
+	It("should wait for the default client", func() {
+		pool := dexapi.NewPool(nil)
+		_, err := pool.Get("")
+		Expect(errors.Is(err, dexapi.ErrNotConnected)).To(BeTrue())
+		Expect(pool.Clients()).To(BeEmpty())
+
+		first, second := newClient(), newClient()
+		pool.SetDefault(first)
+		Expect(pool.Get("")).To(BeIdenticalTo(first))
+		pool.SetDefault(second)
+		Expect(pool.Get("")).To(BeIdenticalTo(second))
+		Expect(closed(first)).To(BeTrue())
+		Expect(closed(second)).To(BeFalse())
+		Expect(pool.Clients()).To(Equal(map[string]dexapi.Interface{"": second}))
+	})
+
+	It("should keep a client per server", func() {
+		def := newClient()
+		pool := dexapi.NewPool(def)
+		_, err := pool.Get("partners")
+		Expect(errors.Is(err, dexapi.ErrUnknownServer)).To(BeTrue())
+		Expect(pool.Hash("partners")).To(BeEmpty())
+
+		first, second := newClient(), newClient()
+		pool.Set("partners", first, "v1")
+		Expect(pool.Get("partners")).To(BeIdenticalTo(first))
+		Expect(pool.Hash("partners")).To(Equal("v1"))
+		pool.Set("partners", first, "v1")
+		Expect(closed(first)).To(BeFalse())
+		pool.Set("partners", second, "v2")
+		Expect(pool.Hash("partners")).To(Equal("v2"))
+		Expect(closed(first)).To(BeTrue())
+		Expect(pool.Clients()).To(Equal(map[string]dexapi.Interface{"": def, "partners": second}))
+
+		pool.Remove("partners")
+		Expect(closed(second)).To(BeTrue())
+		_, err = pool.Get("partners")
+		Expect(errors.Is(err, dexapi.ErrUnknownServer)).To(BeTrue())
+		Expect(closed(def)).To(BeFalse())
+	})
+})